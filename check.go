@@ -0,0 +1,77 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// reportFile is the html report written by flyway check, next to its json report
+const reportFile = "/flyway/reports/report.html"
+
+// CheckOptions configures the build database flyway check migrates from scratch to compare with the target
+// database, and where to copy the html report to.
+type CheckOptions struct {
+	BuildURL      string
+	BuildUser     string
+	BuildPassword string
+	// HTMLReportPath is the host path the html report is copied to, the report is not copied when empty
+	HTMLReportPath string
+}
+
+// Check runs flyway check -changes -drift against the target database configured by the options, e.g. with
+// WithDatabaseUrl, and returns the parsed report. The changes compare the pending migrations applied to the build
+// database with the target database, the drift compares the target database with its schema history.
+// Check requires a Teams or Enterprise license, the error matches ErrTeamsFeature with the community edition.
+func Check(ctx context.Context, checkOpts CheckOptions, opts ...testcontainers.ContainerCustomizer) (*CheckResult, error) {
+	if checkOpts.BuildURL == "" || checkOpts.BuildUser == "" {
+		return nil, errors.New("invalid check options: the build database url and user are required")
+	}
+
+	runOpts := append(slices.Clone(opts),
+		withEnvSetting(flywayEnvCheckBuildUrlKey, checkOpts.BuildURL),
+		withEnvSetting(flywayEnvCheckBuildUserKey, checkOpts.BuildUser),
+		withEnvSetting(flywayEnvCheckBuildPasswordKey, checkOpts.BuildPassword),
+		withEnvSetting(flywayEnvReportFilenameKey, reportFile),
+		WithCommand(CommandCheck),
+		WithOutputType(OutputTypeJSON),
+		testcontainers.CustomizeRequestOption(func(req *testcontainers.GenericContainerRequest) error {
+			req.Cmd = append(req.Cmd, "-changes", "-drift")
+			return nil
+		}),
+	)
+
+	flywayContainer, err := RunContainer(ctx, runOpts...)
+	if err != nil {
+		if flywayContainer != nil {
+			err = errors.Join(err, flywayContainer.Terminate(ctx))
+		}
+		return nil, fmt.Errorf("failed to run check: %w", err)
+	}
+
+	result, err := checkResult(ctx, flywayContainer, checkOpts.HTMLReportPath)
+	if err = errors.Join(err, flywayContainer.Terminate(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to run check: %w", err)
+	}
+	return result, nil
+}
+
+// checkResult decodes the report of the check container, and copies the html report to the host path if any
+func checkResult(ctx context.Context, flywayContainer *FlywayContainer, htmlReportPath string) (*CheckResult, error) {
+	result := &CheckResult{}
+	if err := flywayContainer.DecodeOutput(ctx, result); err != nil {
+		return nil, err
+	}
+
+	if htmlReportPath == "" {
+		return result, nil
+	}
+
+	if err := copyFromContainer(ctx, flywayContainer, reportFile, htmlReportPath); err != nil {
+		return nil, fmt.Errorf("failed to copy html report: %w", err)
+	}
+	return result, nil
+}
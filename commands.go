@@ -0,0 +1,395 @@
+package flyway
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Reset cleans the schemas and migrates them again, so that the database is left freshly migrated.
+// Cleaning must be enabled with WithCleanDisabled(false).
+func (c *FlywayContainer) Reset(ctx context.Context) error {
+	if !cleanEnabled(c.req) {
+		return fmt.Errorf("failed to reset: %w", errCleanDisabled)
+	}
+
+	_, err := c.run(ctx, []string{CommandClean, CommandMigrate})
+	if err != nil {
+		return fmt.Errorf("failed to reset: %w", err)
+	}
+	return nil
+}
+
+// Clean drops all objects of the configured schemas and returns the cleaned schemas.
+// Cleaning must be enabled with WithCleanDisabled(false).
+func (c *FlywayContainer) Clean(ctx context.Context) ([]string, error) {
+	if !cleanEnabled(c.req) {
+		return nil, fmt.Errorf("failed to clean: %w", errCleanDisabled)
+	}
+
+	output, err := c.run(ctx, []string{CommandClean}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean: %w", err)
+	}
+
+	return parseClean(output)
+}
+
+// Migrate runs flyway migrate again against the database, in a new container built from the request of c along
+// with the options, e.g. WithMigrations with more migrations, or in the same container with WithDaemonMode, and
+// returns its result. The options are kept for the later commands of c, so that it can be called again.
+// A failed migration is reported through the result. With WithMigrationsReadOnly, the migrations of WithMigrations
+// are mounted in place of the previous ones, which cannot be done in daemon mode.
+func (c *FlywayContainer) Migrate(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*MigrateResult, error) {
+	req, err := c.migrateRequest(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrate: %w", err)
+	}
+
+	next := newFlywayContainer(c.Container, req, c.settings)
+	output, err := next.run(ctx, []string{CommandMigrate}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+
+	var migrationErr *MigrationError
+	if err != nil && (!errors.As(err, &migrationErr) || output == "") {
+		return nil, fmt.Errorf("failed to run migrate: %w", err)
+	}
+
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrate: %w", err)
+	}
+	result, err := parseMigrate(json.RawMessage(content))
+	if err != nil {
+		return nil, err
+	}
+
+	c.req = req
+	return result, nil
+}
+
+// migrateRequest customizes a clone of the request of c with the options of Migrate, remounting the read-only
+// migrations when the options provide other ones
+func (c *FlywayContainer) migrateRequest(opts ...testcontainers.CustomizeRequestOption) (testcontainers.GenericContainerRequest, error) {
+	req, err := cloneRequest(c.req)
+	if err != nil {
+		return req, err
+	}
+	for _, opt := range opts {
+		if err := opt(&req); err != nil {
+			return req, err
+		}
+	}
+	if err := parseRequest(req, c.settings.readOnly); err != nil {
+		return req, err
+	}
+
+	if c.settings.readOnly && hasMigrationsFiles(req) {
+		if c.settings.daemon {
+			return req, errors.New("invalid read-only migrations: the migrations mounted in daemon mode cannot be replaced")
+		}
+		if err := withReadOnlyMigrations(&req); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// Repair runs flyway repair, which removes the failed migrations from the schema history and realigns the
+// checksums of the applied migrations with the local ones.
+func (c *FlywayContainer) Repair(ctx context.Context) (*RepairResult, error) {
+	output, err := c.run(ctx, []string{CommandRepair}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run repair: %w", err)
+	}
+
+	return parseRepair(output)
+}
+
+// Baseline runs flyway baseline, which marks the existing schema as migrated up to the version configured with
+// WithBaselineVersion, and returns that version.
+func (c *FlywayContainer) Baseline(ctx context.Context) (string, error) {
+	output, err := c.run(ctx, []string{CommandBaseline}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+	if err != nil {
+		return "", fmt.Errorf("failed to run baseline: %w", err)
+	}
+
+	return parseBaseline(output)
+}
+
+// Undo runs flyway undo, which undoes the most recently applied versioned migration with its undo script.
+// Undo requires a Teams or Enterprise license, the error matches ErrTeamsFeature with the community edition.
+func (c *FlywayContainer) Undo(ctx context.Context) (*UndoResult, error) {
+	output, err := c.run(ctx, []string{CommandUndo}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run undo: %w", err)
+	}
+
+	return parseUndo(output)
+}
+
+// Snapshot runs flyway snapshot, which captures the schema of the database, and copies the snapshot to the
+// host path, e.g. to check the drift of another database against it.
+// Snapshot requires a Teams or Enterprise license, the error matches ErrTeamsFeature with the community edition.
+func (c *FlywayContainer) Snapshot(ctx context.Context, hostOutputPath string) error {
+	if hostOutputPath == "" {
+		return errors.New("failed to run snapshot: the host output path is empty")
+	}
+
+	_, err := c.runThen(ctx, func(container *FlywayContainer) error {
+		return copyFromContainer(ctx, container, snapshotFile, hostOutputPath)
+	}, []string{CommandSnapshot}, flywayArg(flywayArgSnapshotFilenameKey, snapshotFile))
+	if err != nil {
+		return fmt.Errorf("failed to run snapshot: %w", err)
+	}
+	return nil
+}
+
+// Info runs flyway info and returns the migrations it reports, both applied and pending.
+func (c *FlywayContainer) Info(ctx context.Context) ([]MigrationInfo, error) {
+	output, err := c.run(ctx, []string{CommandInfo}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run info: %w", err)
+	}
+
+	return parseInfo(output)
+}
+
+// AppliedVersions returns the versions of the migrations flyway info reports as successfully applied, in order of
+// application. Repeatable migrations have no version and are returned with their description instead.
+func (c *FlywayContainer) AppliedVersions(ctx context.Context) ([]string, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := slices.DeleteFunc(info, func(migration MigrationInfo) bool {
+		return migration.State != "Success"
+	})
+	slices.SortStableFunc(applied, func(a, b MigrationInfo) int {
+		return a.InstalledOn.Compare(b.InstalledOn)
+	})
+
+	versions := make([]string, 0, len(applied))
+	for _, migration := range applied {
+		if migration.Version == "" {
+			versions = append(versions, migration.Description)
+			continue
+		}
+		versions = append(versions, migration.Version)
+	}
+	return versions, nil
+}
+
+// NoVersion is the version of a schema without any successfully applied versioned migration, e.g. an empty or
+// only baselined schema
+const NoVersion = ""
+
+// CurrentVersion returns the highest version of the versioned migrations flyway info reports as successfully
+// applied, or NoVersion when there is none.
+func (c *FlywayContainer) CurrentVersion(ctx context.Context) (string, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return NoVersion, err
+	}
+
+	current := NoVersion
+	for _, migration := range info {
+		if migration.State == "Success" && migration.Version != "" && (current == NoVersion || compareVersions(migration.Version, current) > 0) {
+			current = migration.Version
+		}
+	}
+	return current, nil
+}
+
+// AssertUpToDate runs flyway info and returns an error matching ErrPendingMigrations, which lists the pending
+// migrations, unless the schema is up to date. The outdated repeatable migrations, which migrate would apply
+// again, are pending too.
+func (c *FlywayContainer) AssertUpToDate(ctx context.Context) error {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, migration := range info {
+		if migration.State != "Pending" && migration.State != "Outdated" {
+			continue
+		}
+		if migration.Version == "" {
+			pending = append(pending, fmt.Sprintf("repeatable %q (%s)", migration.Description, migration.State))
+			continue
+		}
+		pending = append(pending, fmt.Sprintf("%s %q", migration.Version, migration.Description))
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending: %s", ErrPendingMigrations, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// compareVersions compares the flyway versions part by part, numerically, e.g. 2.10 is higher than 2.9
+func compareVersions(a, b string) int {
+	split := func(version string) []string {
+		return strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' })
+	}
+
+	aParts, bParts := split(a), split(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart string
+		if i < len(aParts) {
+			aPart = strings.TrimLeft(aParts[i], "0")
+		}
+		if i < len(bParts) {
+			bPart = strings.TrimLeft(bParts[i], "0")
+		}
+
+		if c := cmp.Compare(len(aPart), len(bPart)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Validate runs flyway validate and returns the migrations which failed the validation. A failed validation
+// is reported through the result, the error is only returned when the result cannot be read.
+func (c *FlywayContainer) Validate(ctx context.Context) (*ValidateResult, error) {
+	output, err := c.run(ctx, []string{CommandValidate}, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON))
+
+	var migrationErr *MigrationError
+	if err != nil && (!errors.As(err, &migrationErr) || output == "") {
+		return nil, fmt.Errorf("failed to run validate: %w", err)
+	}
+
+	return parseValidate(output)
+}
+
+// run runs the flyway commands to completion in a new container built from the request of c, keeping the
+// command line arguments along with the given ones, and returns its output. The output of failed commands
+// is returned along with the *MigrationError, as it describes the failure.
+func (c *FlywayContainer) run(ctx context.Context, commands []string, extraArgs ...string) (string, error) {
+	return c.runThen(ctx, nil, commands, extraArgs...)
+}
+
+// runThen is run, calling then with the container before it is terminated when the commands succeeded,
+// e.g. to copy the files written by flyway
+func (c *FlywayContainer) runThen(ctx context.Context, then func(*FlywayContainer) error, commands []string, extraArgs ...string) (string, error) {
+	req, err := cloneRequest(c.req)
+	if err != nil {
+		return "", err
+	}
+
+	req.Cmd = slices.Clone(commands)
+	if req.Name != "" {
+		req.Name = strings.Join(append([]string{c.req.Name}, commands...), "-")
+	}
+	for _, arg := range c.req.Cmd {
+		if strings.HasPrefix(arg, "-") && !slices.ContainsFunc(extraArgs, func(extraArg string) bool {
+			return argKey(extraArg) == argKey(arg)
+		}) {
+			req.Cmd = append(req.Cmd, arg)
+		}
+	}
+	req.Cmd = append(req.Cmd, extraArgs...)
+	req.WaitingFor = waitForCommands(waitTimeout(c.req.WaitingFor), req.Cmd)
+
+	if c.settings.daemon {
+		return c.execThen(ctx, req, then)
+	}
+
+	container, runErr := startContainer(ctx, req, c.settings)
+	if container == nil {
+		return "", redactSecrets(req, runErr)
+	}
+
+	output, err := containerOutput(ctx, container)
+	if runErr == nil && then != nil {
+		err = errors.Join(err, then(container))
+	}
+	if err = errors.Join(runErr, err, container.Terminate(ctx)); err != nil {
+		return output, redactSecrets(req, err)
+	}
+	return output, nil
+}
+
+// copyFromContainer copies the file written by flyway in the container to the host path
+func copyFromContainer(ctx context.Context, container testcontainers.Container, containerPath, hostPath string) error {
+	reader, err := container.CopyFileFromContainer(ctx, containerPath)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", containerPath, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", containerPath, err)
+	}
+	if err := os.WriteFile(hostPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", containerPath, err)
+	}
+	return nil
+}
+
+// argKey returns the key of the flyway command line argument
+func argKey(arg string) string {
+	key, _, _ := strings.Cut(arg, "=")
+	return key
+}
+
+// cloneRequest copies the request so that it can start another container, rewinding the file readers
+func cloneRequest(req testcontainers.GenericContainerRequest) (testcontainers.GenericContainerRequest, error) {
+	clone := req
+	clone.Env = maps.Clone(req.Env)
+	clone.Files = slices.Clone(req.Files)
+	clone.Reuse = false
+
+	for _, file := range clone.Files {
+		if file.Reader == nil {
+			continue
+		}
+
+		seeker, ok := file.Reader.(io.Seeker)
+		if !ok {
+			return clone, fmt.Errorf("failed to copy %s again: reader cannot be rewound", file.ContainerFilePath)
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return clone, fmt.Errorf("failed to copy %s again: %w", file.ContainerFilePath, err)
+		}
+	}
+
+	return clone, nil
+}
+
+// flywayOutput returns the output of the flyway commands the container ran
+func (c *FlywayContainer) flywayOutput(ctx context.Context) (string, error) {
+	if c.settings.daemon || c.removed {
+		return c.output, nil
+	}
+	return containerOutput(ctx, c)
+}
+
+// containerOutput returns the logs of the container
+func containerOutput(ctx context.Context, container testcontainers.Container) (string, error) {
+	logs, err := container.Logs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	defer logs.Close()
+
+	output, err := io.ReadAll(logs)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,169 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// daemonEntrypoint keeps the container running, so that the flyway commands are executed in it
+var daemonEntrypoint = []string{"sleep", "infinity"}
+
+// WithDaemonMode keeps the container running instead of running flyway as its process. The flyway commands
+// are executed in the container, the commands of the options first, then those of the command methods like
+// (*FlywayContainer).Info, so that they do not start new containers. As the commands are not the container
+// process, their output is not part of the container logs, e.g. for WithLogWriter and WithProgress.
+func WithDaemonMode() Option {
+	return func(o *options) error {
+		o.daemon = true
+		return nil
+	}
+}
+
+// reuseLockFile serializes the flyway commands executed in a container shared with WithReuse, even by the
+// test binaries of several packages
+const reuseLockFile = "/tmp/flyway.lock"
+
+// WithReuse shares the container with the given name between the tests, including those of other packages run
+// by the same go test, instead of starting a container per test. It implies WithDaemonMode: the first test creates
+// the container, the others attach to it, and the flyway commands are executed in it one at a time. The
+// container is not removed by Terminate, but by the testcontainers reaper once the tests completed, so the
+// image and the migrations of the tests sharing the name should be identical.
+func WithReuse(name string) Option {
+	return func(o *options) error {
+		if !containerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid reuse name %q: expected letters, digits, '_', '.' or '-'", name)
+		}
+
+		o.reuse = name
+		o.daemon = true
+		return nil
+	}
+}
+
+// startDaemon starts the container running daemonEntrypoint and executes the flyway commands of the request in it
+func startDaemon(ctx context.Context, req testcontainers.GenericContainerRequest, settings options) (*FlywayContainer, error) {
+	daemonReq := req
+	daemonReq.Entrypoint = daemonEntrypoint
+	daemonReq.Cmd = nil
+	daemonReq.WaitingFor = nil
+
+	container, err := genericContainer(ctx, daemonReq, settings.startupTimeout)
+	if err != nil {
+		if container != nil {
+			err = errors.Join(err, terminateDaemon(ctx, container, settings))
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, errors.Join(ctxErr, err)
+		}
+		return nil, err
+	}
+
+	c := newFlywayContainer(container, req, settings)
+	if settings.reuse != "" {
+		// a reused container only has the files of the request which created it, copied from the start again
+		var filesReq testcontainers.GenericContainerRequest
+		if filesReq, err = cloneRequest(req); err == nil {
+			err = copyFiles(ctx, container, filesReq.Files)
+		}
+	}
+	if err == nil {
+		c.output, c.exitCode, err = c.exec(ctx, req)
+	}
+	if err != nil {
+		return nil, errors.Join(err, terminateDaemon(ctx, container, settings))
+	}
+	if c.exitCode != 0 {
+		return c, newOutputMigrationError(req.Cmd, c.exitCode, []byte(c.output), settings.outputTailSize)
+	}
+	return c, nil
+}
+
+// terminateDaemon terminates the daemon container, unless it is shared with WithReuse and may still be used
+func terminateDaemon(ctx context.Context, container testcontainers.Container, settings options) error {
+	if settings.reuse != "" {
+		return nil
+	}
+	return terminateCancelled(ctx, container)
+}
+
+// execThen executes the flyway commands of the request in the daemon container, after copying the files of the
+// request again so that new migrations are picked up, then calls then with the container when they succeeded
+func (c *FlywayContainer) execThen(ctx context.Context, req testcontainers.GenericContainerRequest, then func(*FlywayContainer) error) (string, error) {
+	if c.Container == nil {
+		return "", errors.New("failed to run flyway: container is not started")
+	}
+
+	if err := copyFiles(ctx, c.Container, req.Files); err != nil {
+		return "", err
+	}
+
+	output, exitCode, err := c.exec(ctx, req)
+	if err != nil {
+		return "", redactSecrets(req, err)
+	}
+	if exitCode != 0 {
+		return output, redactSecrets(req, newOutputMigrationError(req.Cmd, exitCode, []byte(output), c.settings.outputTailSize))
+	}
+
+	if then != nil {
+		if err := then(c); err != nil {
+			return output, redactSecrets(req, err)
+		}
+	}
+	return output, nil
+}
+
+// exec executes the flyway command line of the request with its environment, bounded by the timeout of its
+// wait strategy, and returns the combined output and the exit code of flyway
+func (c *FlywayContainer) exec(ctx context.Context, req testcontainers.GenericContainerRequest) (string, int, error) {
+	execCtx, cancel := context.WithTimeout(ctx, waitTimeout(req.WaitingFor))
+	defer cancel()
+
+	env := make([]string, 0, len(req.Env))
+	for key, value := range req.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+
+	cmd := commandLine(req)
+	if c.settings.reuse != "" {
+		cmd = append([]string{"flock", reuseLockFile}, cmd...)
+	}
+
+	exitCode, reader, err := c.Exec(execCtx, cmd, tcexec.Multiplexed(), tcexec.WithEnv(env))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to exec flyway: %w", err)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read flyway output: %w", err)
+	}
+	return string(output), exitCode, nil
+}
+
+// copyFiles copies the files into the container, like testcontainers does when creating it
+func copyFiles(ctx context.Context, container testcontainers.Container, files []testcontainers.ContainerFile) error {
+	for _, file := range files {
+		var err error
+		if file.Reader != nil {
+			var content []byte
+			if content, err = io.ReadAll(file.Reader); err == nil {
+				err = container.CopyToContainer(ctx, content, file.ContainerFilePath, file.FileMode)
+			}
+		} else {
+			err = container.CopyFileToContainer(ctx, file.HostFilePath, file.ContainerFilePath, file.FileMode)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file.ContainerFilePath, err)
+		}
+	}
+	return nil
+}
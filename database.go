@@ -0,0 +1,216 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// databaseConnection is the connection to a database container, as derived by WithDatabaseContainer
+type databaseConnection struct {
+	network  string
+	url      string
+	user     string
+	password string
+	// properties are the JDBC properties the driver requires to connect to the database container
+	properties map[string]string
+}
+
+// WithDatabaseContainer sets the database url, user and password from the database container, e.g. of a
+// testcontainers database module, so that they do not have to be kept in sync with the network aliases and
+// the settings of the database. The postgres, mysql, mariadb and mssql images are supported, with the database,
+// the user and the password of their environment variables, e.g. POSTGRES_DB, POSTGRES_USER and POSTGRES_PASSWORD.
+//
+// The containers must share a network, the database being reached through its alias on it, e.g. by
+// WithNetworkAndAlias with the network of the database. Without a network, the flyway container joins the network
+// of the database.
+func WithDatabaseContainer(ctx context.Context, dbContainer testcontainers.Container) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if dbContainer == nil {
+			return errors.New("invalid database container: container is nil")
+		}
+
+		inspect, err := dbContainer.Inspect(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to inspect database container: %w", err)
+		}
+
+		conn, err := resolveDatabaseConnection(inspect, req.Networks)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(req.Networks, conn.network) {
+			req.Networks = append(req.Networks, conn.network)
+		}
+		opts := []testcontainers.CustomizeRequestOption{WithDatabaseUrl(conn.url), WithUser(conn.user)}
+		if conn.password != "" {
+			opts = append(opts, WithPassword(conn.password))
+		}
+		if len(conn.properties) > 0 {
+			opts = append(opts, WithJdbcProperties(conn.properties))
+		}
+
+		for _, opt := range opts {
+			if err := opt(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// resolveDatabaseConnection derives the connection to the database container from its image, its environment
+// variables, and its alias on the network it shares with flyway, one of networks, or its first one otherwise
+func resolveDatabaseConnection(inspect *types.ContainerJSON, networks []string) (databaseConnection, error) {
+	if inspect.Config == nil || inspect.NetworkSettings == nil {
+		return databaseConnection{}, errors.New("invalid database container: container is not created")
+	}
+
+	env := map[string]string{}
+	for _, v := range inspect.Config.Env {
+		if key, value, ok := strings.Cut(v, "="); ok {
+			env[key] = value
+		}
+	}
+
+	network, host, err := databaseHost(inspect, networks)
+	if err != nil {
+		return databaseConnection{}, err
+	}
+	conn := databaseConnection{network: network}
+
+	image := inspect.Config.Image
+	switch family := databaseFamily(image); family {
+	case "postgres":
+		conn.user = env["POSTGRES_USER"]
+		if conn.user == "" {
+			conn.user = "postgres"
+		}
+		conn.password = env["POSTGRES_PASSWORD"]
+		db := env["POSTGRES_DB"]
+		if db == "" {
+			db = conn.user
+		}
+		conn.url = PostgresNetworkURL(host, "", db)
+	case "mysql", "mariadb":
+		prefixes := []string{"MYSQL_"}
+		if family == "mariadb" {
+			// the MARIADB_ variables take precedence over their MYSQL_ equivalents
+			prefixes = []string{"MARIADB_", "MYSQL_"}
+		}
+		get := func(name string) string {
+			keys := make([]string, 0, len(prefixes))
+			for _, prefix := range prefixes {
+				keys = append(keys, prefix+name)
+			}
+			return firstEnv(env, keys...)
+		}
+
+		db := get("DATABASE")
+		if db == "" {
+			return databaseConnection{}, fmt.Errorf("invalid database container: no database created by %s, expected %sDATABASE to be set", image, prefixes[0])
+		}
+		conn.user, conn.password = get("USER"), get("PASSWORD")
+		if conn.user == "" {
+			conn.user, conn.password = "root", get("ROOT_PASSWORD")
+		}
+
+		if family == "mysql" {
+			conn.url = MySQLNetworkURL(host, "", db)
+			// the password of the caching_sha2_password authentication is exchanged with the public key of the server
+			conn.properties = map[string]string{"allowPublicKeyRetrieval": "true"}
+		} else {
+			conn.url = MariaDBNetworkURL(host, "", db)
+		}
+	case "mssql":
+		conn.user = "sa"
+		conn.password = firstEnv(env, "MSSQL_SA_PASSWORD", "SA_PASSWORD")
+		conn.url = SQLServerNetworkURL(host, "", "master")
+	default:
+		return databaseConnection{}, fmt.Errorf("unsupported database image %q: expected a postgres, mysql, mariadb or mssql image", image)
+	}
+
+	return conn, nil
+}
+
+// databaseFamily returns the database family of the image, e.g. postgres for postgres:16-alpine or postgis/postgis,
+// or an empty string when it is not supported
+func databaseFamily(image string) string {
+	name := image
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name = name[:i]
+	}
+
+	switch base := path.Base(name); {
+	case base == "postgres" || base == "postgis":
+		return "postgres"
+	case base == "mysql" || base == "mariadb":
+		return base
+	case strings.Contains(name, "mssql"):
+		return "mssql"
+	}
+	return ""
+}
+
+// databaseHost returns the network shared with flyway and the alias of the database on it, the container name
+// when it has none. Without flyway networks, the first network of the database in name order is used.
+func databaseHost(inspect *types.ContainerJSON, networks []string) (string, string, error) {
+	dbNetworks := make([]string, 0, len(inspect.NetworkSettings.Networks))
+	for name := range inspect.NetworkSettings.Networks {
+		// the aliases only resolve on user-defined networks
+		if name != "bridge" && name != "host" && name != "none" {
+			dbNetworks = append(dbNetworks, name)
+		}
+	}
+	sort.Strings(dbNetworks)
+
+	var network string
+	switch {
+	case len(dbNetworks) == 0:
+		return "", "", errors.New("invalid database container: it is not attached to a user-defined network, which flyway could reach it on")
+	case len(networks) == 0:
+		network = dbNetworks[0]
+	default:
+		for _, name := range networks {
+			if slices.Contains(dbNetworks, name) {
+				network = name
+				break
+			}
+		}
+		if network == "" {
+			return "", "", fmt.Errorf("invalid database container: flyway and the database share no network, flyway is on %s and the database on %s",
+				strings.Join(networks, ", "), strings.Join(dbNetworks, ", "))
+		}
+	}
+
+	if endpoint := inspect.NetworkSettings.Networks[network]; endpoint != nil {
+		for _, alias := range endpoint.Aliases {
+			// docker adds the short container id to the aliases
+			if alias != "" && !(len(alias) == 12 && strings.HasPrefix(inspect.ID, alias)) {
+				return network, alias, nil
+			}
+		}
+	}
+	return network, strings.TrimPrefix(inspect.Name, "/"), nil
+}
+
+// firstEnv returns the first non-empty value of the environment variables
+func firstEnv(env map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := env[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
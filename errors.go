@@ -0,0 +1,286 @@
+package flyway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// defaultOutputTailSize is the maximum number of bytes of the container output kept in a MigrationError
+const defaultOutputTailSize = 4 * 1024
+
+// ErrTeamsFeature is matched by the errors of flyway commands which require a Teams or Enterprise license,
+// like CommandUndo, when run by the community edition
+var ErrTeamsFeature = errors.New("flyway teams feature: a teams or enterprise license is required")
+
+// ErrNonEmptySchema is matched by the errors of flyway when it refuses to migrate a schema which is not empty
+// but has no schema history table, which must be baselined first, e.g. with WithBaselineVersion and Baseline
+var ErrNonEmptySchema = errors.New("flyway non-empty schema: the schema must be baselined first")
+
+// ErrValidationFailed is matched by the errors of flyway when the applied migrations failed the validation
+var ErrValidationFailed = errors.New("flyway validation failed")
+
+// ErrConnection is matched by the errors of flyway when it cannot connect to the database
+var ErrConnection = errors.New("flyway connection failed: the database cannot be reached")
+
+// ErrSuccessMarkerMissing is matched by the error of RunContainer when the output of flyway does not contain the
+// marker set by WithSuccessMarker
+var ErrSuccessMarkerMissing = errors.New("flyway success marker missing")
+
+// ErrPendingMigrations is matched by the error of (*FlywayContainer).AssertUpToDate when migrations are pending
+var ErrPendingMigrations = errors.New("flyway pending migrations")
+
+// flyway error codes of the json error output
+const (
+	errorCodeValidate         = "VALIDATE_ERROR"
+	errorCodeConnection       = "DB_CONNECTION"
+	errorCodeFailedVersioned  = "FAILED_VERSIONED_MIGRATION"
+	errorCodeFailedRepeatable = "FAILED_REPEATABLE_MIGRATION"
+)
+
+var (
+	validationFailedPattern = regexp.MustCompile(`(?i)validate failed`)
+	connectionPattern       = regexp.MustCompile(`(?i)unable to obtain connection from database`)
+	migrationFailedPattern  = regexp.MustCompile(`Migration (\S+) failed`)
+	sqlStatePattern         = regexp.MustCompile(`SQL State\s*:\s*(\S+)`)
+	scriptVersionPattern    = regexp.MustCompile(`^V([^_]+)__`)
+)
+
+// teamsFeaturePattern matches the output of flyway when a command requires a Teams or Enterprise license
+var teamsFeaturePattern = regexp.MustCompile(`(?i)edition upgrade required|not supported by flyway community edition`)
+
+// nonEmptySchemaPattern matches the output of flyway when it finds a non-empty schema without schema history
+var nonEmptySchemaPattern = regexp.MustCompile(`(?i)found non-empty schema\(s\).*(without|but no) schema history table`)
+
+// MigrationError is returned when flyway exits with a non-zero code, it carries the tail of the container output
+type MigrationError struct {
+	ExitCode int
+	Command  string
+	Output   string
+}
+
+func (e *MigrationError) Error() string {
+	if output := strings.TrimSpace(e.Output); output != "" {
+		return fmt.Sprintf("flyway %s failed with exit code %d: %s", e.Command, e.ExitCode, output)
+	}
+	return fmt.Sprintf("flyway %s failed with exit code %d", e.Command, e.ExitCode)
+}
+
+// Is reports whether the output of flyway shows the failure was caused by a missing Teams or Enterprise license,
+// ErrTeamsFeature, by a non-empty schema without schema history, ErrNonEmptySchema, by a failed validation,
+// ErrValidationFailed, or by a failed connection, ErrConnection
+func (e *MigrationError) Is(target error) bool {
+	code, message := e.failure()
+	switch target {
+	case ErrTeamsFeature:
+		return teamsFeaturePattern.MatchString(message)
+	case ErrNonEmptySchema:
+		return nonEmptySchemaPattern.MatchString(message)
+	case ErrValidationFailed:
+		return code == errorCodeValidate || validationFailedPattern.MatchString(message)
+	case ErrConnection:
+		return code == errorCodeConnection || connectionPattern.MatchString(message)
+	}
+	return false
+}
+
+// As sets a *MigrationFailedError target when the output of flyway shows a migration failed
+func (e *MigrationError) As(target any) bool {
+	failedErr, ok := target.(**MigrationFailedError)
+	if !ok {
+		return false
+	}
+
+	code, message := e.failure()
+	match := migrationFailedPattern.FindStringSubmatch(message)
+	if match == nil && code != errorCodeFailedVersioned && code != errorCodeFailedRepeatable {
+		return false
+	}
+
+	*failedErr = &MigrationFailedError{}
+	if match != nil {
+		(*failedErr).Script = match[1]
+		if version := scriptVersionPattern.FindStringSubmatch(path.Base(match[1])); version != nil {
+			(*failedErr).Version = version[1]
+		}
+	}
+	if sqlState := sqlStatePattern.FindStringSubmatch(message); sqlState != nil {
+		(*failedErr).SQLState = sqlState[1]
+	}
+	return true
+}
+
+// failure returns the error code and the message of the flyway json error output, or the plain output otherwise
+func (e *MigrationError) failure() (string, string) {
+	content, err := extractJSON(e.Output)
+	if err != nil {
+		return "", e.Output
+	}
+
+	var output struct {
+		Error *errorOutput `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(content), &output); err != nil || output.Error == nil {
+		return "", e.Output
+	}
+	return output.Error.ErrorCode, output.Error.message()
+}
+
+// MigrationFailedError describes the migration which failed, the version is empty for repeatable migrations
+type MigrationFailedError struct {
+	Version  string
+	Script   string
+	SQLState string
+}
+
+func (e *MigrationFailedError) Error() string {
+	return fmt.Sprintf("flyway migration %s failed with sql state %s", e.Script, e.SQLState)
+}
+
+// TimeoutError is returned when flyway did not complete within the timeout of WithTimeout, it carries the tail
+// of the container output and the migration flyway was applying, e.g. "1 - seed data", if any
+type TimeoutError struct {
+	Timeout   time.Duration
+	Migration string
+	Output    string
+	err       error
+}
+
+func (e *TimeoutError) Error() string {
+	message := fmt.Sprintf("flyway did not complete within %s", e.Timeout)
+	if e.Migration != "" {
+		message += fmt.Sprintf(" while applying migration %s", e.Migration)
+	}
+	if output := strings.TrimSpace(e.Output); output != "" {
+		message += ": " + output
+	}
+	return message
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.err
+}
+
+// newTimeoutError reads the last outputTailSize bytes of the container output, defaultOutputTailSize when unset,
+// and the last migration flyway started applying
+func newTimeoutError(ctx context.Context, container testcontainers.Container, timeout time.Duration, err error, outputTailSize int) *TimeoutError {
+	if outputTailSize <= 0 {
+		outputTailSize = defaultOutputTailSize
+	}
+
+	timeoutErr := &TimeoutError{Timeout: timeout, err: err}
+	output, outputErr := containerOutput(ctx, container)
+	if outputErr != nil {
+		return timeoutErr
+	}
+
+	timeoutErr.Output = string(tail([]byte(output), outputTailSize))
+	if matches := migratingPattern.FindAllStringSubmatch(output, -1); len(matches) > 0 {
+		match := matches[len(matches)-1]
+		timeoutErr.Migration = match[2]
+		if match[1] != "" {
+			timeoutErr.Migration = match[1] + " - " + match[2]
+		}
+	}
+	return timeoutErr
+}
+
+// newCancelledError wraps the error of the cancelled ctx with the last outputTailSize bytes of the container
+// output, defaultOutputTailSize when unset, which shows the migration flyway was applying
+func newCancelledError(ctx context.Context, container testcontainers.Container, outputTailSize int) error {
+	if outputTailSize <= 0 {
+		outputTailSize = defaultOutputTailSize
+	}
+
+	outputCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), terminateTimeout)
+	defer cancel()
+
+	output, err := containerOutput(outputCtx, container)
+	if output = strings.TrimSpace(output); err != nil || output == "" {
+		return ctx.Err()
+	}
+	return fmt.Errorf("flyway cancelled: %w: %s", ctx.Err(), tail([]byte(output), outputTailSize))
+}
+
+// newMigrationError reads the last outputTailSize bytes of the container output, defaultOutputTailSize when unset
+func newMigrationError(ctx context.Context, container testcontainers.Container, cmd []string, exitCode, outputTailSize int) *MigrationError {
+	logs, err := container.Logs(ctx)
+	if err != nil {
+		return newOutputMigrationError(cmd, exitCode, nil, outputTailSize)
+	}
+	defer logs.Close()
+
+	output, _ := io.ReadAll(logs)
+	return newOutputMigrationError(cmd, exitCode, output, outputTailSize)
+}
+
+// newOutputMigrationError keeps the last outputTailSize bytes of the flyway output, defaultOutputTailSize when unset
+func newOutputMigrationError(cmd []string, exitCode int, output []byte, outputTailSize int) *MigrationError {
+	if outputTailSize <= 0 {
+		outputTailSize = defaultOutputTailSize
+	}
+
+	return &MigrationError{
+		ExitCode: exitCode,
+		Command:  strings.Join(cmd, " "),
+		Output:   string(tail(output, outputTailSize)),
+	}
+}
+
+// tail returns at most the last size bytes of the output, starting at a line boundary when the output is cut
+func tail(output []byte, size int) []byte {
+	if len(output) <= size {
+		return output
+	}
+
+	output = output[len(output)-size:]
+	if i := bytes.IndexByte(output, '\n'); i >= 0 {
+		output = output[i+1:]
+	}
+	return output
+}
+
+// redactedError masks the sensitive values of the request in the message of the wrapped error
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// minRedactedSecretLength is the length under which a secret is not searched in a free text, where a value as
+// short as a few characters would mask fragments of unrelated words
+const minRedactedSecretLength = 6
+
+// redactValues masks the values of the sensitive settings of the request wherever they appear in s
+func redactValues(req testcontainers.GenericContainerRequest, s string) string {
+	for _, key := range sensitiveEnvKeys {
+		if secret := req.Env[key]; len(secret) >= minRedactedSecretLength {
+			s = strings.ReplaceAll(s, secret, "******")
+		}
+	}
+	return s
+}
+
+func redactSecrets(req testcontainers.GenericContainerRequest, err error) error {
+	message := redactValues(req, err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, message: message}
+}
@@ -0,0 +1,109 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/CyberOwlTeam/flyway"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcclickhouse "github.com/testcontainers/testcontainers-go/modules/clickhouse"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+)
+
+const (
+	clickhouseImage    = "clickhouse/clickhouse-server:24.3-alpine"
+	clickhouseSrvName  = "clickhouse"
+	clickhouseDbName   = "test_db"
+	clickhouseUsername = "test_user"
+	clickhousePassword = "test_password"
+
+	// clickhouseJdbcJarURL is the driver the clickhouse plugin of flyway is built against, which the flyway image
+	// does not ship
+	clickhouseJdbcJarURL = "https://repo1.maven.org/maven2/com/clickhouse/clickhouse-jdbc/0.6.0/clickhouse-jdbc-0.6.0-all.jar"
+)
+
+func TestClickHouse(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	clickhouseContainer, err := tcclickhouse.RunContainer(ctx,
+		testcontainers.WithImage(clickhouseImage),
+		tcnetwork.WithNetwork([]string{clickhouseSrvName}, nw),
+		tcclickhouse.WithDatabase(clickhouseDbName),
+		tcclickhouse.WithUsername(clickhouseUsername),
+		tcclickhouse.WithPassword(clickhousePassword),
+	)
+	require.NoError(t, err, "failed creating clickhouse container")
+	t.Cleanup(func() {
+		require.NoError(t, clickhouseContainer.Terminate(ctx), "failed to terminate clickhouse container")
+	})
+
+	// the driver jar is downloaded unless a local copy is set, e.g. to run the example offline
+	jarPath := os.Getenv("CLICKHOUSE_JDBC_JAR")
+	if jarPath == "" {
+		jarPath = downloadJar(t, ctx, clickhouseJdbcJarURL)
+	}
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseUrl(flyway.ClickHouseURL(clickhouseSrvName, "", clickhouseDbName)),
+		flyway.WithUser(clickhouseUsername),
+		flyway.WithPassword(clickhousePassword),
+		flyway.WithDriverJar(jarPath),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	// the test reaches clickhouse through the mapped native port on the host
+	connStr, err := clickhouseContainer.ConnectionString(ctx)
+	require.NoError(t, err, "failed to get connection string")
+
+	db, err := sql.Open("clickhouse", connStr)
+	require.NoError(t, err, "failed to open database")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close(), "failed to close database")
+	})
+
+	var name string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT name FROM events WHERE id = 1").Scan(&name), "failed to query events")
+	require.Equal(t, "migrated", name)
+}
+
+// downloadJar downloads the jar into a temporary directory of the test and returns its path
+func downloadJar(t *testing.T, ctx context.Context, url string) string {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err, "failed to create the request")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "failed to download %s", url)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "failed to download %s", url)
+
+	jarPath := filepath.Join(t.TempDir(), path.Base(url))
+	file, err := os.Create(jarPath)
+	require.NoError(t, err, "failed to create %s", jarPath)
+	_, err = io.Copy(file, resp.Body)
+	require.NoError(t, errors.Join(err, file.Close()), "failed to write %s", jarPath)
+
+	return jarPath
+}
@@ -0,0 +1,8 @@
+CREATE TABLE events
+(
+    id         UInt64,
+    name       String,
+    created_at DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+ORDER BY (id);
@@ -0,0 +1,2 @@
+INSERT INTO events (id, name)
+VALUES (1, 'migrated');
@@ -0,0 +1,94 @@
+package cockroachdb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CyberOwlTeam/flyway"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	cockroachImage    = "cockroachdb/cockroach:v23.2.6"
+	cockroachSrvName  = "cockroach"
+	cockroachPort     = "26257"
+	cockroachDbName   = "defaultdb"
+	cockroachUsername = "root"
+)
+
+func TestCockroachDB(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	cockroachContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          cockroachImage,
+			Networks:       []string{nw.Name},
+			NetworkAliases: map[string][]string{nw.Name: {cockroachSrvName}},
+			Cmd:            []string{"start-single-node", "--insecure"},
+			ExposedPorts:   []string{cockroachPort + "/tcp", "8080/tcp"},
+			WaitingFor:     wait.ForHTTP("/health?ready=1").WithPort("8080/tcp"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "failed creating cockroachdb container")
+	t.Cleanup(func() {
+		require.NoError(t, cockroachContainer.Terminate(ctx), "failed to terminate cockroachdb container")
+	})
+
+	// cockroachdb speaks the postgres protocol: flyway uses the postgres driver and detects cockroachdb itself.
+	// The insecure node trusts its clients, it accepts no password and no TLS.
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseUrl(flyway.CockroachURL(cockroachSrvName, cockroachPort, cockroachDbName, map[string]string{"sslmode": "disable"})),
+		flyway.WithUser(cockroachUsername),
+		flyway.WithPassword(""),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	logs, err := flywayContainer.Logs(ctx)
+	require.NoError(t, err, "failed to read flyway logs")
+	defer logs.Close()
+	output, err := io.ReadAll(logs)
+	require.NoError(t, err, "failed to read flyway logs")
+	require.Contains(t, string(output), "(CockroachDB", "expected flyway to detect cockroachdb")
+
+	// the test reaches cockroachdb through its mapped port on the host, reading the stored column from the index
+	host, err := cockroachContainer.Host(ctx)
+	require.NoError(t, err, "failed to get the cockroachdb host")
+	port, err := cockroachContainer.MappedPort(ctx, cockroachPort+"/tcp")
+	require.NoError(t, err, "failed to get the cockroachdb port")
+
+	db, err := sql.Open("postgres", fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=disable", cockroachUsername, host, port.Port(), cockroachDbName))
+	require.NoError(t, err, "failed to open database")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close(), "failed to close database")
+	})
+
+	var name string
+	var createdAt time.Time
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT name, created_at FROM stuff@stuff_name_idx").Scan(&name, &createdAt), "failed to query the stuff index")
+	require.Equal(t, "migrated", name)
+	require.False(t, createdAt.IsZero(), "expected the stored column to be read from the index")
+}
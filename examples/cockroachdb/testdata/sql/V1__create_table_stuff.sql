@@ -0,0 +1,6 @@
+CREATE TABLE stuff
+(
+    id         UUID        NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       STRING      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
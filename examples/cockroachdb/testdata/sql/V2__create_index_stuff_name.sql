@@ -0,0 +1,6 @@
+-- STORING is cockroachdb syntax, which postgres rejects. It covers a non-key column, as cockroachdb rejects storing
+-- the primary key, which every index already contains
+CREATE INDEX stuff_name_idx ON stuff (name) STORING (created_at);
+
+INSERT INTO stuff (name)
+VALUES ('migrated');
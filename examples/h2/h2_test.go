@@ -0,0 +1,41 @@
+package h2_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/CyberOwlTeam/flyway"
+	"github.com/stretchr/testify/require"
+)
+
+// the in-memory database lives as long as the flyway process, DB_CLOSE_DELAY keeps it open between the commands
+const h2Url = "jdbc:h2:mem:test_db;DB_CLOSE_DELAY=-1"
+
+func TestH2(t *testing.T) {
+	ctx := context.Background()
+
+	// the flyway image ships the H2 driver, so no database container is needed
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithDatabaseUrl(h2Url),
+		flyway.WithUser("sa"),
+		flyway.WithPassword("sa"),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+		flyway.WithOutputType(flyway.OutputTypeJSON),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	// the database is gone once flyway exits, the schema history is read from the info run along with migrate
+	var info struct {
+		Migrations []flyway.MigrationInfo `json:"migrations"`
+	}
+	require.NoError(t, flywayContainer.DecodeCommandOutput(ctx, flyway.CommandInfo, &info), "failed to decode info output")
+	require.Len(t, info.Migrations, 1)
+	require.Equal(t, "1", info.Migrations[0].Version)
+	require.Equal(t, "Success", info.Migrations[0].State)
+}
@@ -0,0 +1,5 @@
+CREATE TABLE stuff
+(
+    id   INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
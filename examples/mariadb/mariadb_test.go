@@ -0,0 +1,78 @@
+package mariadb_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/CyberOwlTeam/flyway"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcmariadb "github.com/testcontainers/testcontainers-go/modules/mariadb"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+)
+
+const (
+	mariadbImage    = "mariadb:11"
+	mariadbSrvName  = "mariadb"
+	mariadbDbName   = "test_db"
+	mariadbUsername = "test_user"
+	mariadbPassword = "test_password"
+)
+
+func TestMariaDB(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	mariadbContainer, err := tcmariadb.RunContainer(ctx,
+		testcontainers.WithImage(mariadbImage),
+		tcnetwork.WithNetwork([]string{mariadbSrvName}, nw),
+		tcmariadb.WithDatabase(mariadbDbName),
+		tcmariadb.WithUsername(mariadbUsername),
+		tcmariadb.WithPassword(mariadbPassword),
+	)
+	require.NoError(t, err, "failed creating mariadb container")
+	t.Cleanup(func() {
+		require.NoError(t, mariadbContainer.Terminate(ctx), "failed to terminate mariadb container")
+	})
+
+	// the jdbc:mariadb url and the credentials are derived from the container, the flyway image ships the MariaDB
+	// driver, which is selected for the jdbc:mariadb urls
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseContainer(ctx, mariadbContainer),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	info, err := flywayContainer.Info(ctx)
+	require.NoError(t, err, "failed to run info")
+	require.Len(t, info, 1)
+	require.Equal(t, "Success", info[0].State)
+
+	// the test reaches mariadb through its mapped port on the host
+	connStr, err := mariadbContainer.ConnectionString(ctx)
+	require.NoError(t, err, "failed to get connection string")
+
+	db, err := sql.Open("mysql", connStr)
+	require.NoError(t, err, "failed to open database")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close(), "failed to close database")
+	})
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stuff").Scan(&count), "expected the stuff table to exist")
+}
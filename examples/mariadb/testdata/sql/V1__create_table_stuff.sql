@@ -0,0 +1,5 @@
+CREATE TABLE stuff
+(
+    id   INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
@@ -0,0 +1,75 @@
+package mssql_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CyberOwlTeam/flyway"
+	_ "github.com/microsoft/go-mssqldb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcmssql "github.com/testcontainers/testcontainers-go/modules/mssql"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	mssqlImage    = "mcr.microsoft.com/mssql/server:2022-CU13-ubuntu-22.04"
+	mssqlSrvName  = "mssql"
+	mssqlDbName   = "master"
+	mssqlPassword = "Test_Password1"
+)
+
+func TestSQLServer(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	mssqlContainer, err := tcmssql.RunContainer(ctx,
+		testcontainers.WithImage(mssqlImage),
+		tcnetwork.WithNetwork([]string{mssqlSrvName}, nw),
+		tcmssql.WithAcceptEULA(),
+		tcmssql.WithPassword(mssqlPassword),
+		testcontainers.WithWaitStrategy(wait.ForLog("Recovery is complete.").WithStartupTimeout(2*time.Minute)),
+	)
+	require.NoError(t, err, "failed creating mssql container")
+	t.Cleanup(func() {
+		require.NoError(t, mssqlContainer.Terminate(ctx), "failed to terminate mssql container")
+	})
+
+	// the jdbc:sqlserver url of the master database and the sa credentials are derived from the container, the url
+	// trusts the self-signed certificate of the server, which the driver refuses otherwise
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseContainer(ctx, mssqlContainer),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	// the test reaches mssql through its mapped port on the host, trusting its self-signed certificate as well
+	connStr, err := mssqlContainer.ConnectionString(ctx, "database="+mssqlDbName, "TrustServerCertificate=true")
+	require.NoError(t, err, "failed to get connection string")
+
+	db, err := sql.Open("sqlserver", connStr)
+	require.NoError(t, err, "failed to open database")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close(), "failed to close database")
+	})
+
+	var name string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT name FROM stuff").Scan(&name), "failed to query stuff")
+	require.Equal(t, "migrated", name)
+}
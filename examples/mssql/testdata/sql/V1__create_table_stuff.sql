@@ -0,0 +1,8 @@
+CREATE TABLE stuff
+(
+    id   INT IDENTITY (1, 1) NOT NULL PRIMARY KEY,
+    name NVARCHAR(255)       NOT NULL
+);
+
+INSERT INTO stuff (name)
+VALUES (N'migrated');
@@ -0,0 +1,88 @@
+package oracle_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CyberOwlTeam/flyway"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	oracleImage    = "gvenzl/oracle-free:23-slim-faststart"
+	oracleSrvName  = "oracle"
+	oracleService  = "FREEPDB1"
+	oracleUsername = "test_user"
+	oraclePassword = "test_password"
+	// the database is created on the first start, which takes minutes on a slow runner even with a faststart image
+	oracleStartupTimeout = 5 * time.Minute
+)
+
+func TestOracle(t *testing.T) {
+	if os.Getenv("TEST_ORACLE") == "" {
+		t.Skip("the oracle image is large and slow to start, set TEST_ORACLE to run this test")
+	}
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	oracleContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          oracleImage,
+			Networks:       []string{nw.Name},
+			NetworkAliases: map[string][]string{nw.Name: {oracleSrvName}},
+			Env: map[string]string{
+				"ORACLE_PASSWORD":   oraclePassword,
+				"APP_USER":          oracleUsername,
+				"APP_USER_PASSWORD": oraclePassword,
+			},
+			ExposedPorts: []string{"1521/tcp"},
+			// the listener accepts connections before the pluggable database and the app user are created
+			WaitingFor: wait.ForLog("DATABASE IS READY TO USE!").WithStartupTimeout(oracleStartupTimeout),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "failed creating oracle container")
+	t.Cleanup(func() {
+		require.NoError(t, oracleContainer.Terminate(ctx), "failed to terminate oracle container")
+	})
+
+	// the PL/SQL blocks of the migrations end with a / line, which flyway splits the statements on
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseUrl(flyway.OracleURL(oracleSrvName, "", oracleService)),
+		flyway.WithUser(oracleUsername),
+		flyway.WithPassword(oraclePassword),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	query := "SET HEADING OFF FEEDBACK OFF PAGESIZE 0\nSELECT name FROM stuff;\nEXIT\n"
+	exitCode, reader, err := oracleContainer.Exec(ctx, []string{
+		"bash", "-c", "echo \"$QUERY\" | sqlplus -s " + oracleUsername + "/" + oraclePassword + "@//localhost/" + oracleService,
+	}, tcexec.Multiplexed(), tcexec.WithEnv([]string{"QUERY=" + query}))
+	require.NoError(t, err, "failed to query oracle")
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err, "failed to read the query output")
+	require.Equal(t, 0, exitCode, "failed to query oracle: %s", output)
+	require.Equal(t, "migrated", strings.TrimSpace(string(output)), "expected the procedure to have inserted the row")
+}
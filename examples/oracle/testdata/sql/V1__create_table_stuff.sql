@@ -0,0 +1,11 @@
+CREATE TABLE stuff
+(
+    id   NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR2(255) NOT NULL
+);
+
+CREATE OR REPLACE PROCEDURE add_stuff(p_name IN VARCHAR2) AS
+BEGIN
+    INSERT INTO stuff (name) VALUES (p_name);
+END;
+/
@@ -0,0 +1,4 @@
+BEGIN
+    add_stuff('migrated');
+END;
+/
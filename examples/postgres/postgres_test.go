@@ -0,0 +1,80 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CyberOwlTeam/flyway"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	tcnetwork "github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	postgresImage    = "postgres:16-alpine"
+	postgresSrvName  = "postgres"
+	postgresDbName   = "test_db"
+	postgresUsername = "test_user"
+	postgresPassword = "test_password"
+)
+
+func TestPostgres(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := tcnetwork.New(ctx)
+	require.NoError(t, err, "failed creating network")
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx), "failed to remove network")
+	})
+
+	postgresContainer, err := tcpostgres.RunContainer(ctx,
+		testcontainers.WithImage(postgresImage),
+		tcnetwork.WithNetwork([]string{postgresSrvName}, nw),
+		tcpostgres.WithDatabase(postgresDbName),
+		tcpostgres.WithUsername(postgresUsername),
+		tcpostgres.WithPassword(postgresPassword),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	require.NoError(t, err, "failed creating postgres container")
+	t.Cleanup(func() {
+		require.NoError(t, postgresContainer.Terminate(ctx), "failed to terminate postgres container")
+	})
+
+	// the jdbc:postgresql url and the credentials are derived from the container, flyway reaches postgres through
+	// its alias on the shared network
+	flywayContainer, err := flyway.RunContainer(ctx,
+		flyway.WithNetworkAndAlias(nw, "flyway"),
+		flyway.WithDatabaseContainer(ctx, postgresContainer),
+		flyway.WithMigrations(filepath.Join("testdata", "sql")),
+	)
+	if flywayContainer != nil {
+		t.Cleanup(func() {
+			require.NoError(t, flywayContainer.Terminate(ctx), "failed to terminate flyway container")
+		})
+	}
+	require.NoError(t, err, "failed to run container")
+
+	// the test reaches postgres through its mapped port on the host
+	connStr, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err, "failed to get connection string")
+
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err, "failed to open database")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close(), "failed to close database")
+	})
+
+	var name string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT name FROM stuff").Scan(&name), "failed to query stuff")
+	require.Equal(t, "migrated", name)
+}
@@ -0,0 +1,5 @@
+CREATE TABLE stuff
+(
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
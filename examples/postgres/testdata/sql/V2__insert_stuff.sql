@@ -0,0 +1 @@
+INSERT INTO stuff (name) VALUES ('migrated');
@@ -27,13 +27,13 @@ func ExampleRunContainer() {
 
 	flywayContainer, err := flyway.RunContainer(ctx,
 		testcontainers.WithImage(flyway.BuildFlywayImageVersion()),
-		tcnetwork.WithNetwork([]string{"flyway"}, nw),
+		flyway.WithNetworkAndAlias(nw, "flyway"),
 		flyway.WithDatabaseUrl(postgresContainer.getNetworkUrl()),
 		flyway.WithUser(defaultPostgresDbUsername),
 		flyway.WithPassword(defaultPostgresDbPassword),
 		flyway.WithConnectRetries(3),
 		flyway.WithTable("my_schema_history"),
-		flyway.WithGroup("my_group"),
+		flyway.WithGroup(true),
 		flyway.WithTimeout(1*time.Minute),
 		flyway.WithMigrations(filepath.Join("testdata", flyway.DefaultMigrationsPath)),
 	)
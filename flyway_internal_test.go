@@ -0,0 +1,544 @@
+package flyway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestWithCommandArgs_ordering(t *testing.T) {
+	req, _, err := buildRequest(
+		WithCommandArgs("-skipExecutingMigrations=true"),
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+		WithColor(ColorAlways),
+		WithCommandArgs("-placeholders.greeting=hello", "-color=never"),
+		WithTablespace("flyway_ts"),
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		CommandMigrate, CommandInfo, "-color=always", "-tablespace=flyway_ts",
+		"-skipExecutingMigrations=true", "-placeholders.greeting=hello", "-color=never",
+	}, req.Cmd, "expected the raw arguments last, in order")
+}
+
+func TestWithMigrationsReadOnly(t *testing.T) {
+	opts := []testcontainers.ContainerCustomizer{
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+		WithMigrationsReadOnly(true),
+	}
+	req, _, err := buildRequest(opts...)
+	require.NoError(t, err)
+	require.Empty(t, req.Files, "expected the migrations to be mounted rather than copied")
+
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	absPath, err := filepath.Abs(filepath.Join("testdata", DefaultMigrationsPath))
+	require.NoError(t, err)
+	require.Equal(t, []mount.Mount{{Type: mount.TypeBind, Source: absPath, Target: DefaultMigrationsPath, ReadOnly: true}}, hostConfig.Mounts)
+
+	_, _, err = buildRequest(append(opts, WithMigrationsFS(os.DirFS(filepath.Join("testdata", "repeatable", "sql"))))...)
+	require.ErrorContains(t, err, "only WithMigrations can be mounted")
+}
+
+func TestWithMigrationsReadOnly_migrate(t *testing.T) {
+	opts := []testcontainers.ContainerCustomizer{
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+		WithMigrationsReadOnly(true),
+	}
+	req, settings, err := buildRequest(opts...)
+	require.NoError(t, err)
+
+	c := newFlywayContainer(nil, req, settings)
+	next, err := c.migrateRequest()
+	require.NoError(t, err, "expected the mounted migrations to be kept")
+	require.Empty(t, next.Files)
+
+	next, err = c.migrateRequest(WithMigrations(filepath.Join("testdata", "repeatable", "sql")))
+	require.NoError(t, err)
+	require.Empty(t, next.Files, "expected the migrations to be mounted rather than copied")
+	hostConfig := &container.HostConfig{}
+	next.HostConfigModifier(hostConfig)
+	absPath, err := filepath.Abs(filepath.Join("testdata", "repeatable", "sql"))
+	require.NoError(t, err)
+	require.Equal(t, []mount.Mount{{Type: mount.TypeBind, Source: absPath, Target: DefaultMigrationsPath, ReadOnly: true}}, hostConfig.Mounts, "expected the migrations to be remounted")
+
+	_, err = c.migrateRequest(WithMigrationsFS(os.DirFS(filepath.Join("testdata", "repeatable", "sql"))))
+	require.ErrorContains(t, err, "only WithMigrations can be mounted")
+
+	settings.daemon = true
+	_, err = newFlywayContainer(nil, req, settings).migrateRequest(WithMigrations(filepath.Join("testdata", "repeatable", "sql")))
+	require.ErrorContains(t, err, "cannot be replaced")
+}
+
+func TestWithRawArgs(t *testing.T) {
+	req, _, err := buildRequest(
+		WithRawArgs("-mixed=true", "-outOfOrder=false", "-user=other"),
+		WithCommandArgs("-outOfOrder=true"),
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+		WithOutputType(OutputTypeJSON),
+	)
+	require.NoError(t, err, "expected the connection arguments to be accepted")
+
+	require.Equal(t, []string{
+		CommandMigrate, CommandInfo, "-color=never", "-outputType=json", "-outOfOrder=true", "-mixed=true", "-outOfOrder=false", "-user=other",
+	}, req.Cmd, "expected the raw arguments after the command arguments, in order")
+
+	settings := options{}
+	require.Error(t, WithRawArgs("migrate")(&settings), "expected the commands to be rejected")
+}
+
+func TestWithDaemonMode(t *testing.T) {
+	req, settings, err := buildRequest(
+		WithDaemonMode(),
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+	)
+	require.NoError(t, err)
+
+	require.True(t, settings.daemon)
+	require.Equal(t, []string{CommandMigrate, CommandInfo, "-color=never"}, req.Cmd, "expected the flyway command line to be kept for exec")
+	require.Empty(t, req.Entrypoint, "expected the entrypoint to be replaced when the container starts only")
+}
+
+func TestWithReuse(t *testing.T) {
+	req, settings, err := buildRequest(
+		WithReuse("flyway-shared"),
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+	)
+	require.NoError(t, err)
+
+	require.True(t, settings.daemon, "expected reuse to imply daemon mode")
+	require.Equal(t, "flyway-shared", req.Name)
+	require.True(t, req.Reuse)
+
+	require.Error(t, WithReuse("flyway shared")(&options{}), "expected an invalid name to be rejected")
+}
+
+func TestRetryable(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		err       error
+		retryable bool
+	}{
+		{
+			name:      "connection failed",
+			ctx:       context.Background(),
+			err:       &MigrationError{ExitCode: 1, Output: "ERROR: Unable to obtain connection from database (jdbc:postgresql://pgdb:5432/test_db)"},
+			retryable: true,
+		},
+		{
+			name: "sql failure",
+			ctx:  context.Background(),
+			err:  &MigrationError{ExitCode: 1, Output: "ERROR: Migration V2__create_table_stuff_again.sql failed\nSQL State  : 42P07"},
+		},
+		{
+			name: "timeout",
+			ctx:  context.Background(),
+			err:  &TimeoutError{Timeout: time.Minute, err: context.DeadlineExceeded},
+		},
+		{
+			name:      "image pull failed",
+			ctx:       context.Background(),
+			err:       errors.New("failed to create container: failed to pull image"),
+			retryable: true,
+		},
+		{
+			name: "cancelled",
+			ctx:  cancelledCtx,
+			err:  errors.New("failed to create container: context canceled"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			require.Equal(tt, testCase.retryable, retryable(testCase.ctx, testCase.err))
+		})
+	}
+}
+
+func TestShared_startupFailed(t *testing.T) {
+	t.Cleanup(func() {
+		shared.container, shared.err, shared.refs = nil, nil, 0
+	})
+
+	_, err := Shared(context.Background(), WithUser("test_user"))
+	require.ErrorContains(t, err, "failed to start shared flyway container")
+
+	_, again := Shared(context.Background(),
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+	)
+	require.Equal(t, err.Error(), again.Error(), "expected the startup error rather than a new container")
+}
+
+func TestShared_startupCanceled(t *testing.T) {
+	t.Cleanup(func() {
+		shared.container, shared.err, shared.refs = nil, nil, 0
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Shared(ctx,
+		WithDatabaseUrl("jdbc:postgresql://pgdb:5432/test_db"),
+		WithUser("test_user"),
+		WithPassword("test_password"),
+		WithMigrations(filepath.Join("testdata", DefaultMigrationsPath)),
+	)
+	require.ErrorContains(t, err, "failed to start shared flyway container")
+	require.NoError(t, shared.err, "expected the canceled startup not to be kept")
+
+	_, again := Shared(context.Background(), WithUser("test_user"))
+	require.ErrorContains(t, again, "failed to start shared flyway container")
+	require.NotEqual(t, err.Error(), again.Error(), "expected a new startup rather than the canceled one")
+	require.Error(t, shared.err)
+}
+
+func TestWithCommandArgs_invalid(t *testing.T) {
+	for _, arg := range []string{"-url=jdbc:postgresql://other:5432/test_db", "-user=other", "-password=secret", "migrate"} {
+		arg := arg
+		t.Run(arg, func(tt *testing.T) {
+			settings := options{}
+			require.Error(tt, WithCommandArgs(arg)(&settings))
+			require.Empty(tt, settings.commandArgs)
+		})
+	}
+}
+
+func TestDescribeCommand_shortSecret(t *testing.T) {
+	req := testcontainers.GenericContainerRequest{ContainerRequest: testcontainers.ContainerRequest{
+		Env: map[string]string{
+			flywayEnvUrlKey:        "jdbc:postgresql://pgdb:5432/test_db",
+			flywayEnvPasswordKey:   "p",
+			flywayEnvVaultTokenKey: "t",
+			envTimeZoneKey:         "Europe/Paris",
+		},
+		Cmd: []string{CommandMigrate, "-password=p", "-vault.token=t"},
+	}}
+	require.Equal(t, []string{
+		"FLYWAY_PASSWORD=******",
+		"FLYWAY_URL=jdbc:postgresql://pgdb:5432/test_db",
+		"FLYWAY_VAULT_TOKEN=******",
+		"TZ=Europe/Paris",
+		"flyway", "migrate", "-password=******", "-vault.token=******",
+	}, describeCommand(req), "expected the secrets to be masked by key only")
+
+	err := errors.New("failed to connect to jdbc:postgresql://pgdb:5432/test_db in Europe/Paris")
+	require.Same(t, err, redactSecrets(req, err), "expected a short secret not to be masked in the message")
+
+	req.Env[flywayEnvPasswordKey] = "test_password"
+	require.EqualError(t, redactSecrets(req, errors.New("password test_password is invalid")), "password ****** is invalid")
+}
+
+func TestParseMigrate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected *MigrateResult
+	}{
+		{
+			name: "migrations",
+			output: `WARNING: Storing migrations in 'sql' is not recommended
+{
+  "initialSchemaVersion": null,
+  "targetSchemaVersion": "2",
+  "migrations": [
+    {"version": "1", "description": "create table one", "executionTime": 12},
+    {"version": "2", "description": "create table two", "executionTime": 3}
+  ],
+  "migrationsExecuted": 2,
+  "success": true,
+  "warnings": ["Storing migrations in 'sql' is not recommended"],
+  "operation": "migrate"
+}
+`,
+			expected: &MigrateResult{
+				TargetSchemaVersion: "2",
+				MigrationsExecuted:  2,
+				Success:             true,
+				Warnings:            []string{"Storing migrations in 'sql' is not recommended"},
+				Migrations: []MigrateMigration{
+					{Version: "1", Description: "create table one", ExecutionTime: 12 * time.Millisecond},
+					{Version: "2", Description: "create table two", ExecutionTime: 3 * time.Millisecond},
+				},
+			},
+		},
+		{
+			name:   "no migrations",
+			output: `{"initialSchemaVersion": "2", "targetSchemaVersion": "2", "migrations": [], "migrationsExecuted": 0, "success": true, "warnings": [], "operation": "migrate"}`,
+			expected: &MigrateResult{
+				InitialSchemaVersion: "2",
+				TargetSchemaVersion:  "2",
+				Success:              true,
+				Warnings:             []string{},
+			},
+		},
+		{
+			name:     "failed",
+			output:   `{"error": {"errorCode": "FAULT", "message": "Migration V2__two.sql failed"}}`,
+			expected: &MigrateResult{ErrorMessage: "Migration V2__two.sql failed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			content, err := extractJSON(testCase.output)
+			require.NoError(tt, err)
+
+			result, err := parseMigrate(json.RawMessage(content))
+			require.NoError(tt, err)
+			require.Equal(tt, testCase.expected, result)
+		})
+	}
+}
+
+func TestParseMigrateText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected *MigrateResult
+	}{
+		{
+			name: "migrations",
+			output: `Successfully validated 3 migrations (execution time 00:00.020s)
+Current version of schema "public": << Empty Schema >>
+Migrating schema "public" to version "1 - create uuid extension"
+Migrating schema "public" to version "2.1 - create table stuff"
+Migrating schema "public" with repeatable migration "greeting messages"
+Successfully applied 3 migrations to schema "public", now at version v2.1 (execution time 00:00.041s)
+`,
+			expected: &MigrateResult{
+				TargetSchemaVersion: "2.1",
+				MigrationsExecuted:  3,
+				Success:             true,
+				Migrations: []MigrateMigration{
+					{Version: "1", Description: "create uuid extension"},
+					{Version: "2.1", Description: "create table stuff"},
+					{Description: "greeting messages"},
+				},
+			},
+		},
+		{
+			name: "up to date",
+			output: `Successfully validated 3 migrations (execution time 00:00.020s)
+Current version of schema "public": 2.2
+Schema "public" is up to date. No migration necessary.
+`,
+			expected: &MigrateResult{InitialSchemaVersion: "2.2", TargetSchemaVersion: "2.2", Success: true},
+		},
+		{
+			name: "no migrations",
+			output: `WARNING: No migrations found. Are your locations set up correctly?
+Successfully validated 0 migrations (execution time 00:00.010s)
+Current version of schema "public": << Empty Schema >>
+Schema "public" is up to date. No migration necessary.
+`,
+			expected: &MigrateResult{Success: true, Warnings: []string{"No migrations found. Are your locations set up correctly?"}},
+		},
+		{
+			name: "failed",
+			output: `WARNING: DB: relation "stuff" already exists, skipping (SQL State: 42P07 - Error Code: 0)
+Current version of schema "public": 1
+Migrating schema "public" to version "2 - create table stuff again"
+ERROR: Migration V2__create_table_stuff_again.sql failed
+`,
+			expected: &MigrateResult{
+				InitialSchemaVersion: "1",
+				TargetSchemaVersion:  "1",
+				Warnings:             []string{`DB: relation "stuff" already exists, skipping (SQL State: 42P07 - Error Code: 0)`},
+				Migrations:           []MigrateMigration{{Version: "2", Description: "create table stuff again"}},
+				ErrorMessage:         "Migration V2__create_table_stuff_again.sql failed",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			require.Equal(tt, testCase.expected, parseMigrateText(testCase.output))
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "2.10", b: "2.9", expected: 1},
+		{a: "1", b: "1.0", expected: 0},
+		{a: "2024.06.01", b: "2024.6.1", expected: 0},
+		{a: "2024.06.01", b: "2024.11.30", expected: -1},
+		{a: "1_1", b: "1.2", expected: -1},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.a+" "+testCase.b, func(tt *testing.T) {
+			require.Equal(tt, testCase.expected, compareVersions(testCase.a, testCase.b))
+			require.Equal(tt, -testCase.expected, compareVersions(testCase.b, testCase.a))
+		})
+	}
+}
+
+func TestProgressConsumer(t *testing.T) {
+	var mu sync.Mutex
+	events := []MigrationEvent{}
+	consumer := &progressConsumer{progress: func(event MigrationEvent) {
+		time.Sleep(10 * time.Millisecond) // a slow callback must not hold up the logs
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}}
+
+	for _, content := range []string{
+		"Current version of schema \"public\": << Empty Schema >>\n",
+		"Migrating schema \"public\" to version \"1 - create uuid extension\"\nMigrating schema \"public\" ",
+		"to version \"2.1 - create table stuff\"\n",
+		"Migrating schema \"public\" with repeatable migration \"greeting messages\"\n",
+		"Successfully applied 3 migrations to schema \"public\", now at version v2.1 (execution time 00:00.041s)\n",
+	} {
+		consumer.Accept(testcontainers.Log{LogType: testcontainers.StdoutLog, Content: []byte(content)})
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 3
+	}, 5*time.Second, 10*time.Millisecond, "expected an event per migration")
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, expected := range []MigrationEvent{
+		{Version: "1", Description: "create uuid extension"},
+		{Version: "2.1", Description: "create table stuff"},
+		{Description: "greeting messages"},
+	} {
+		require.Equal(t, expected.Version, events[i].Version)
+		require.Equal(t, expected.Description, events[i].Description)
+		require.GreaterOrEqual(t, events[i].Duration, time.Duration(0))
+	}
+}
+
+func TestResolveDatabaseConnection(t *testing.T) {
+	const id = "3f4e8a9b2c1d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f"
+	containerJSON := func(image string, env []string, networks map[string][]string) *types.ContainerJSON {
+		settings := map[string]*network.EndpointSettings{}
+		for name, aliases := range networks {
+			settings[name] = &network.EndpointSettings{Aliases: aliases}
+		}
+		return &types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: id, Name: "/db-container"},
+			Config:            &container.Config{Image: image, Env: env},
+			NetworkSettings:   &types.NetworkSettings{Networks: settings},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		inspect  *types.ContainerJSON
+		networks []string
+		expected databaseConnection
+		err      string
+	}{
+		{
+			name:     "postgres",
+			inspect:  containerJSON("postgres:16-alpine", []string{"POSTGRES_USER=test_user", "POSTGRES_PASSWORD=test_password", "POSTGRES_DB=test_db"}, map[string][]string{"test_nw": {"pgdb", id[:12]}}),
+			networks: []string{"test_nw"},
+			expected: databaseConnection{network: "test_nw", url: "jdbc:postgresql://pgdb:5432/test_db", user: "test_user", password: "test_password"},
+		},
+		{
+			name:     "postgres defaults",
+			inspect:  containerJSON("docker.io/postgis/postgis:16-3.4", []string{"POSTGRES_PASSWORD=secret"}, map[string][]string{"test_nw": {id[:12]}}),
+			expected: databaseConnection{network: "test_nw", url: "jdbc:postgresql://db-container:5432/postgres", user: "postgres", password: "secret"},
+		},
+		{
+			name:     "mysql",
+			inspect:  containerJSON("mysql:8.4", []string{"MYSQL_USER=app", "MYSQL_PASSWORD=app_password", "MYSQL_DATABASE=orders"}, map[string][]string{"other_nw": {"db"}, "test_nw": {"mysql"}}),
+			networks: []string{"test_nw"},
+			expected: databaseConnection{network: "test_nw", url: "jdbc:mysql://mysql:3306/orders", user: "app", password: "app_password", properties: map[string]string{"allowPublicKeyRetrieval": "true"}},
+		},
+		{
+			name:     "mariadb root",
+			inspect:  containerJSON("mariadb:11", []string{"MARIADB_ROOT_PASSWORD=root_password", "MYSQL_ROOT_PASSWORD=ignored", "MARIADB_DATABASE=test_db"}, map[string][]string{"test_nw": {"mariadb"}}),
+			expected: databaseConnection{network: "test_nw", url: "jdbc:mariadb://mariadb:3306/test_db", user: "root", password: "root_password"},
+		},
+		{
+			name:     "mssql",
+			inspect:  containerJSON("mcr.microsoft.com/mssql/server:2022-latest", []string{"ACCEPT_EULA=Y", "MSSQL_SA_PASSWORD=Str0ng!Passw0rd"}, map[string][]string{"test_nw": {"mssql"}}),
+			expected: databaseConnection{network: "test_nw", url: "jdbc:sqlserver://mssql:1433;databaseName=master;encrypt=true;trustServerCertificate=true", user: "sa", password: "Str0ng!Passw0rd"},
+		},
+		{
+			name:     "no shared network",
+			inspect:  containerJSON("postgres:16", []string{"POSTGRES_PASSWORD=secret"}, map[string][]string{"db_nw": {"pgdb"}}),
+			networks: []string{"flyway_nw"},
+			err:      "flyway and the database share no network, flyway is on flyway_nw and the database on db_nw",
+		},
+		{
+			name:    "default bridge network",
+			inspect: containerJSON("postgres:16", []string{"POSTGRES_PASSWORD=secret"}, map[string][]string{"bridge": nil}),
+			err:     "not attached to a user-defined network",
+		},
+		{
+			name:    "missing mysql database",
+			inspect: containerJSON("mysql:8.4", []string{"MYSQL_ROOT_PASSWORD=secret"}, map[string][]string{"test_nw": {"mysql"}}),
+			err:     "expected MYSQL_DATABASE to be set",
+		},
+		{
+			name:    "unsupported image",
+			inspect: containerJSON("redis:7", nil, map[string][]string{"test_nw": {"redis"}}),
+			err:     `unsupported database image "redis:7"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			conn, err := resolveDatabaseConnection(testCase.inspect, testCase.networks)
+			if testCase.err != "" {
+				require.ErrorContains(tt, err, testCase.err)
+				return
+			}
+			require.NoError(tt, err)
+			require.Equal(tt, testCase.expected, conn)
+		})
+	}
+}
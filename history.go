@@ -0,0 +1,54 @@
+package flyway
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// tableNamePattern matches the schema history table names History accepts, optionally qualified by a schema
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// HistoryEntry is a row of the flyway schema history table. Version is empty for repeatable migrations.
+type HistoryEntry struct {
+	Version     string
+	Description string
+	Type        string
+	InstalledOn time.Time
+	Success     bool
+}
+
+// History reads the schema history table configured with WithTable through the database handle, which the
+// caller opens with the driver of its choice so that no driver is bundled. The entries are in installation order.
+func (c *FlywayContainer) History(ctx context.Context, db *sql.DB) ([]HistoryEntry, error) {
+	table := c.req.Env[flywayEnvTableKey]
+	if table == "" {
+		table = defaultTable
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid schema history table %q", table)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, description, type, installed_on, success FROM "+table+" ORDER BY installed_rank")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		var version sql.NullString
+		if err := rows.Scan(&version, &entry.Description, &entry.Type, &entry.InstalledOn, &entry.Success); err != nil {
+			return nil, fmt.Errorf("failed to read schema history: %w", err)
+		}
+		entry.Version = version.String
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,107 @@
+package flyway
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// MigrationEvent reports a migration applied by flyway, the version is empty for repeatable migrations.
+// The duration is measured from the logs, from the start of the migration to the start of the next one.
+type MigrationEvent struct {
+	Version     string
+	Description string
+	Duration    time.Duration
+}
+
+// WithProgress calls progress with each migration flyway applies, in order, as it reads them from the logs.
+// The events are buffered, so that a slow callback does not hold up the logs. They are only produced by the
+// human-readable output, not by the json output.
+func WithProgress(progress func(event MigrationEvent)) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if progress == nil {
+			return errors.New("invalid progress callback: callback is nil")
+		}
+
+		if req.LogConsumerCfg == nil {
+			req.LogConsumerCfg = &testcontainers.LogConsumerConfig{}
+		}
+		req.LogConsumerCfg.Consumers = append(req.LogConsumerCfg.Consumers, &progressConsumer{progress: progress})
+		return nil
+	}
+}
+
+// progressConsumer is a log consumer parsing the migrations of the logs into events, which it delivers from a
+// goroutine draining the queue, started when there are events and stopped once the queue is empty
+type progressConsumer struct {
+	progress func(event MigrationEvent)
+
+	mu         sync.Mutex
+	partial    string
+	pending    *MigrationEvent
+	started    time.Time
+	queue      []MigrationEvent
+	delivering bool
+}
+
+func (p *progressConsumer) Accept(log testcontainers.Log) {
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lines := strings.Split(p.partial+string(log.Content), "\n")
+	p.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		p.acceptLine(line, now)
+	}
+
+	if len(p.queue) > 0 && !p.delivering {
+		p.delivering = true
+		go p.deliver()
+	}
+}
+
+// acceptLine queues the pending migration once the next one starts or flyway reports the migrations applied
+func (p *progressConsumer) acceptLine(line string, now time.Time) {
+	match := migratingPattern.FindStringSubmatch(line)
+	switch {
+	case match != nil:
+		p.flush(now)
+		p.pending = &MigrationEvent{Version: match[1], Description: match[2]}
+		p.started = now
+	case appliedPattern.MatchString(line):
+		p.flush(now)
+	case strings.HasPrefix(line, "ERROR: "):
+		p.pending = nil
+	}
+}
+
+func (p *progressConsumer) flush(now time.Time) {
+	if p.pending == nil {
+		return
+	}
+
+	p.pending.Duration = now.Sub(p.started)
+	p.queue = append(p.queue, *p.pending)
+	p.pending = nil
+}
+
+func (p *progressConsumer) deliver() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.delivering = false
+			p.mu.Unlock()
+			return
+		}
+		event := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		p.progress(event)
+	}
+}
@@ -0,0 +1,581 @@
+package flyway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// error codes of the migrations which failed the validation, see InvalidMigration
+const (
+	ErrorCodeChecksumMismatch    = "CHECKSUM_MISMATCH"
+	ErrorCodeDescriptionMismatch = "DESCRIPTION_MISMATCH"
+	ErrorCodeTypeMismatch        = "TYPE_MISMATCH"
+	ErrorCodeAppliedNotResolved  = "APPLIED_VERSIONED_MIGRATION_NOT_RESOLVED"
+	ErrorCodeResolvedNotApplied  = "RESOLVED_VERSIONED_MIGRATION_NOT_APPLIED"
+)
+
+// invalidMigrationPatterns match the messages flyway reports invalid migrations with, by error code, for the
+// versions which only report the failed validation as an error
+var invalidMigrationPatterns = map[string]*regexp.Regexp{
+	ErrorCodeChecksumMismatch:    regexp.MustCompile(`Migration checksum mismatch for migration version (\S+)`),
+	ErrorCodeDescriptionMismatch: regexp.MustCompile(`Migration description mismatch for migration version (\S+)`),
+	ErrorCodeTypeMismatch:        regexp.MustCompile(`Migration type mismatch for migration version (\S+)`),
+	ErrorCodeAppliedNotResolved:  regexp.MustCompile(`Detected applied migration not resolved locally: (\S+?)\.?$`),
+	ErrorCodeResolvedNotApplied:  regexp.MustCompile(`Detected resolved migration not applied to database: (\S+?)\.?$`),
+}
+
+// installedOnLayouts are the layouts flyway versions use for the installation time of a migration
+var installedOnLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// MigrationInfo describes a migration as reported by flyway info. Category is Versioned, Repeatable or Baseline.
+// InstalledOn is the zero time and ExecutionTime is zero for pending migrations.
+type MigrationInfo struct {
+	Category      string
+	Version       string
+	Description   string
+	Type          string
+	State         string
+	InstalledOn   time.Time
+	ExecutionTime time.Duration
+	Checksum      int
+}
+
+// ValidateResult is the outcome of flyway validate
+type ValidateResult struct {
+	ValidationSuccessful bool
+	InvalidMigrations    []InvalidMigration
+}
+
+// InvalidMigration is a migration which failed the validation, e.g. with ErrorCodeChecksumMismatch
+type InvalidMigration struct {
+	Version      string
+	Description  string
+	FilePath     string
+	ErrorCode    string
+	ErrorMessage string
+}
+
+// MigrateResult is the outcome of flyway migrate. ErrorMessage is the flyway error when the migration failed.
+type MigrateResult struct {
+	InitialSchemaVersion string
+	TargetSchemaVersion  string
+	MigrationsExecuted   int
+	Success              bool
+	Warnings             []string
+	Migrations           []MigrateMigration
+	ErrorMessage         string
+}
+
+// MigrateMigration is a migration applied by flyway migrate
+type MigrateMigration struct {
+	Version       string
+	Description   string
+	ExecutionTime time.Duration
+}
+
+// Result is the outcome of the flyway commands run by RunAndWait. Migrate is the outcome of flyway migrate,
+// nil when the container did not run it.
+type Result struct {
+	Commands []string
+	ExitCode int
+	Output   string
+	Migrate  *MigrateResult
+}
+
+// MigrateResult returns the outcome of the migrate command the container ran. It parses the json output selected
+// with WithOutputType(OutputTypeJSON), or the human-readable logs otherwise, which have no execution times.
+// A failed migration is reported through the result.
+func (c *FlywayContainer) MigrateResult(ctx context.Context) (*MigrateResult, error) {
+	if !slices.Contains(c.req.Cmd, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON)) {
+		if c.Container == nil {
+			return nil, errors.New("failed to read the migrate result: container is not started")
+		}
+
+		output, err := c.flywayOutput(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the migrate result: %w", err)
+		}
+		return parseMigrateText(output), nil
+	}
+
+	var content json.RawMessage
+	if err := c.DecodeOutput(ctx, &content); err != nil {
+		return nil, err
+	}
+
+	return parseMigrate(content)
+}
+
+// parseMigrate parses the migrate result of the json output, which is an error when the migration failed
+func parseMigrate(content json.RawMessage) (*MigrateResult, error) {
+	var failed struct {
+		Error *errorOutput `json:"error"`
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		if err := json.Unmarshal(content, &failed); err != nil {
+			return nil, fmt.Errorf("failed to parse flyway migrate output: %w", err)
+		}
+		if failed.Error != nil {
+			return &MigrateResult{ErrorMessage: failed.Error.message()}, nil
+		}
+	}
+
+	outputs, err := commandOutputs(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse flyway migrate output: %w", err)
+	}
+
+	for _, output := range outputs {
+		var migrate struct {
+			Operation            string   `json:"operation"`
+			InitialSchemaVersion string   `json:"initialSchemaVersion"`
+			TargetSchemaVersion  string   `json:"targetSchemaVersion"`
+			MigrationsExecuted   int      `json:"migrationsExecuted"`
+			Success              bool     `json:"success"`
+			Warnings             []string `json:"warnings"`
+			Migrations           []struct {
+				Version       string `json:"version"`
+				Description   string `json:"description"`
+				ExecutionTime int64  `json:"executionTime"`
+			} `json:"migrations"`
+		}
+		if err := json.Unmarshal(output, &migrate); err != nil {
+			return nil, fmt.Errorf("failed to parse flyway migrate output: %w", err)
+		}
+		if migrate.Operation != CommandMigrate {
+			continue
+		}
+
+		result := &MigrateResult{
+			InitialSchemaVersion: migrate.InitialSchemaVersion,
+			TargetSchemaVersion:  migrate.TargetSchemaVersion,
+			MigrationsExecuted:   migrate.MigrationsExecuted,
+			Success:              migrate.Success,
+			Warnings:             migrate.Warnings,
+		}
+		for _, migration := range migrate.Migrations {
+			result.Migrations = append(result.Migrations, MigrateMigration{
+				Version:       migration.Version,
+				Description:   migration.Description,
+				ExecutionTime: time.Duration(migration.ExecutionTime) * time.Millisecond,
+			})
+		}
+		return result, nil
+	}
+	return nil, errors.New("failed to parse flyway migrate output: missing output of flyway migrate")
+}
+
+var (
+	currentVersionPattern = regexp.MustCompile(`Current version of schema "[^"]*": (.+)`)
+	migratingPattern      = regexp.MustCompile(`Migrating schema "[^"]*" (?:to version "([^"]+?) - |with repeatable migration ")([^"]*)"`)
+	appliedPattern        = regexp.MustCompile(`Successfully applied (\d+) migrations? to schema "[^"]*"(?:, now at version v(\S+))?`)
+	upToDatePattern       = regexp.MustCompile(`Schema "[^"]*" is up to date`)
+	logLevelPattern       = regexp.MustCompile(`(?m)^(WARNING|ERROR): (.+)$`)
+)
+
+// parseMigrateText parses the migrate result of the human-readable logs. A schema which is up to date is a
+// success without executed migrations.
+func parseMigrateText(output string) *MigrateResult {
+	result := &MigrateResult{}
+	if match := currentVersionPattern.FindStringSubmatch(output); match != nil {
+		if version := strings.TrimSpace(match[1]); version != "<< Empty Schema >>" {
+			result.InitialSchemaVersion = version
+		}
+	}
+	result.TargetSchemaVersion = result.InitialSchemaVersion
+
+	for _, match := range migratingPattern.FindAllStringSubmatch(output, -1) {
+		result.Migrations = append(result.Migrations, MigrateMigration{Version: match[1], Description: match[2]})
+	}
+
+	for _, match := range logLevelPattern.FindAllStringSubmatch(output, -1) {
+		if match[1] == "WARNING" {
+			result.Warnings = append(result.Warnings, strings.TrimSpace(match[2]))
+		} else if result.ErrorMessage == "" {
+			result.ErrorMessage = strings.TrimSpace(match[2])
+		}
+	}
+	if result.ErrorMessage != "" {
+		return result
+	}
+
+	if match := appliedPattern.FindStringSubmatch(output); match != nil {
+		result.Success = true
+		result.MigrationsExecuted, _ = strconv.Atoi(match[1])
+		if match[2] != "" {
+			result.TargetSchemaVersion = match[2]
+		}
+	} else if upToDatePattern.MatchString(output) {
+		result.Success = true
+	}
+	return result
+}
+
+// RepairResult is the outcome of flyway repair
+type RepairResult struct {
+	RepairActions     []string          `json:"repairActions"`
+	MigrationsRemoved []RepairMigration `json:"migrationsRemoved"`
+	MigrationsDeleted []RepairMigration `json:"migrationsDeleted"`
+	MigrationsAligned []RepairMigration `json:"migrationsAligned"`
+}
+
+// RepairMigration is a migration of the schema history repaired by flyway repair
+type RepairMigration struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	FilePath    string `json:"filepath"`
+}
+
+// UndoResult is the outcome of flyway undo
+type UndoResult struct {
+	InitialSchemaVersion string          `json:"initialSchemaVersion"`
+	TargetSchemaVersion  string          `json:"targetSchemaVersion"`
+	UndoneMigrations     []UndoMigration `json:"undoneMigrations"`
+}
+
+// UndoMigration is a versioned migration undone by flyway undo
+type UndoMigration struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	FilePath    string `json:"filepath"`
+}
+
+// CheckResult is the report of flyway check, with one result per check operation, e.g. changes or drift
+type CheckResult struct {
+	IndividualResults []CheckOperationResult `json:"individualResults"`
+}
+
+// CheckOperationResult is the report of a check operation, Raw holds the whole report of the operation
+type CheckOperationResult struct {
+	Operation string
+	Raw       json.RawMessage
+}
+
+// UnmarshalJSON decodes the report of a check operation, keeping it whole as its content depends on the operation
+func (r *CheckOperationResult) UnmarshalJSON(data []byte) error {
+	var operation struct {
+		Operation string `json:"operation"`
+	}
+	if err := json.Unmarshal(data, &operation); err != nil {
+		return err
+	}
+
+	*r = CheckOperationResult{Operation: operation.Operation, Raw: append(json.RawMessage{}, data...)}
+	return nil
+}
+
+// UnmarshalJSON decodes a migration of the flyway json output, tolerating the null and empty values of
+// pending migrations
+func (m *MigrationInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Category       *string `json:"category"`
+		Version        *string `json:"version"`
+		Description    *string `json:"description"`
+		Type           *string `json:"type"`
+		State          *string `json:"state"`
+		InstalledOn    *string `json:"installedOn"`
+		InstalledOnUTC *string `json:"installedOnUTC"`
+		ExecutionTime  *int64  `json:"executionTime"`
+		Checksum       *int    `json:"checksum"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	installedOn, err := parseInstalledOn(value(raw.InstalledOnUTC, value(raw.InstalledOn, "")))
+	if err != nil {
+		return err
+	}
+
+	*m = MigrationInfo{
+		Category:      value(raw.Category, ""),
+		Version:       value(raw.Version, ""),
+		Description:   value(raw.Description, ""),
+		Type:          value(raw.Type, ""),
+		State:         value(raw.State, ""),
+		InstalledOn:   installedOn,
+		ExecutionTime: time.Duration(value(raw.ExecutionTime, 0)) * time.Millisecond,
+		Checksum:      value(raw.Checksum, 0),
+	}
+	return nil
+}
+
+// parseInstalledOn parses the installation time, which flyway reports in UTC
+func parseInstalledOn(installedOn string) (time.Time, error) {
+	if installedOn == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range installedOnLayouts {
+		if t, err := time.ParseInLocation(layout, installedOn, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid installation time %q", installedOn)
+}
+
+// DecodeOutput decodes the json output of the flyway commands the container ran into v, which requires
+// the json output selected with WithOutputType(OutputTypeJSON).
+func (c *FlywayContainer) DecodeOutput(ctx context.Context, v any) error {
+	if !slices.Contains(c.req.Cmd, flywayArg(flywayArgOutputTypeKey, OutputTypeJSON)) {
+		return errors.New("failed to decode output: the output type is not json")
+	}
+
+	output, err := c.flywayOutput(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+
+	content, err := extractJSON(output)
+	if err != nil {
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+	return nil
+}
+
+// parseInfo parses the migrations of the flyway info json output
+func parseInfo(output string) ([]MigrationInfo, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Migrations []MigrationInfo `json:"migrations"`
+	}
+	if err := json.Unmarshal([]byte(content), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse flyway info output: %w", err)
+	}
+	return info.Migrations, nil
+}
+
+// parseClean parses the schemas of the flyway clean json output
+func parseClean(output string) ([]string, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var clean struct {
+		SchemasCleaned []string `json:"schemasCleaned"`
+	}
+	if err := json.Unmarshal([]byte(content), &clean); err != nil {
+		return nil, fmt.Errorf("failed to parse flyway clean output: %w", err)
+	}
+	return clean.SchemasCleaned, nil
+}
+
+// parseBaseline parses the baseline version of the flyway baseline json output
+func parseBaseline(output string) (string, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return "", err
+	}
+
+	var baseline struct {
+		SuccessfullyBaselined bool   `json:"successfullyBaselined"`
+		BaselineVersion       string `json:"baselineVersion"`
+	}
+	if err := json.Unmarshal([]byte(content), &baseline); err != nil {
+		return "", fmt.Errorf("failed to parse flyway baseline output: %w", err)
+	}
+	if !baseline.SuccessfullyBaselined {
+		return "", errors.New("failed to baseline: flyway did not baseline the schema")
+	}
+	return baseline.BaselineVersion, nil
+}
+
+// parseRepair parses the flyway repair json output
+func parseRepair(output string) (*RepairResult, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RepairResult{}
+	if err := json.Unmarshal([]byte(content), result); err != nil {
+		return nil, fmt.Errorf("failed to parse flyway repair output: %w", err)
+	}
+	return result, nil
+}
+
+// parseUndo parses the flyway undo json output
+func parseUndo(output string) (*UndoResult, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UndoResult{}
+	if err := json.Unmarshal([]byte(content), result); err != nil {
+		return nil, fmt.Errorf("failed to parse flyway undo output: %w", err)
+	}
+	return result, nil
+}
+
+// parseValidate parses the flyway validate json output, which is either the validate result or, when the
+// validation failed, an error listing the invalid migrations in its message
+func parseValidate(output string) (*ValidateResult, error) {
+	content, err := extractJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var validate struct {
+		ValidationSuccessful *bool `json:"validationSuccessful"`
+		InvalidMigrations    []struct {
+			Version      string      `json:"version"`
+			Description  string      `json:"description"`
+			FilePath     string      `json:"filepath"`
+			ErrorDetails errorOutput `json:"errorDetails"`
+		} `json:"invalidMigrations"`
+		Error *errorOutput `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(content), &validate); err != nil {
+		return nil, fmt.Errorf("failed to parse flyway validate output: %w", err)
+	}
+
+	if validate.ValidationSuccessful != nil {
+		result := &ValidateResult{ValidationSuccessful: *validate.ValidationSuccessful}
+		for _, migration := range validate.InvalidMigrations {
+			result.InvalidMigrations = append(result.InvalidMigrations, InvalidMigration{
+				Version:      migration.Version,
+				Description:  migration.Description,
+				FilePath:     migration.FilePath,
+				ErrorCode:    migration.ErrorDetails.ErrorCode,
+				ErrorMessage: migration.ErrorDetails.message(),
+			})
+		}
+		return result, nil
+	}
+
+	if validate.Error == nil {
+		return nil, errors.New("failed to parse flyway validate output: missing validation result")
+	}
+
+	result := &ValidateResult{}
+	for _, line := range strings.Split(validate.Error.message(), "\n") {
+		line = strings.TrimSpace(line)
+		for code, pattern := range invalidMigrationPatterns {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				result.InvalidMigrations = append(result.InvalidMigrations, InvalidMigration{
+					Version:      match[1],
+					ErrorCode:    code,
+					ErrorMessage: line,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// errorOutput is an error of the flyway json output, whose message key differs across flyway versions
+type errorOutput struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+	Message      string `json:"message"`
+}
+
+func (e errorOutput) message() string {
+	if e.ErrorMessage != "" {
+		return e.ErrorMessage
+	}
+	return e.Message
+}
+
+// DecodeCommandOutput decodes the json output of one of the flyway commands the container ran into v, e.g. the
+// output of CommandInfo when the container ran CommandMigrate then CommandInfo.
+func (c *FlywayContainer) DecodeCommandOutput(ctx context.Context, command string, v any) error {
+	var content json.RawMessage
+	if err := c.DecodeOutput(ctx, &content); err != nil {
+		return err
+	}
+
+	outputs, err := commandOutputs(content)
+	if err != nil {
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+
+	for _, output := range outputs {
+		var operation struct {
+			Operation string `json:"operation"`
+		}
+		if err := json.Unmarshal(output, &operation); err != nil {
+			return fmt.Errorf("failed to decode output: %w", err)
+		}
+
+		if operation.Operation == command {
+			if err := json.Unmarshal(output, v); err != nil {
+				return fmt.Errorf("failed to decode output: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to decode output: missing output of flyway %s", command)
+}
+
+// commandOutputs splits the json output into the output of each command. With several commands, flyway emits
+// either an array or a composite result holding the individual results.
+func commandOutputs(content json.RawMessage) ([]json.RawMessage, error) {
+	var outputs []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		if err := json.Unmarshal(content, &outputs); err != nil {
+			return nil, err
+		}
+		return outputs, nil
+	}
+
+	var composite struct {
+		Operation         string            `json:"operation"`
+		IndividualResults []json.RawMessage `json:"individualResults"`
+	}
+	if err := json.Unmarshal(content, &composite); err != nil {
+		return nil, err
+	}
+	if composite.Operation == "" && len(composite.IndividualResults) > 0 {
+		return composite.IndividualResults, nil
+	}
+	return []json.RawMessage{content}, nil
+}
+
+// extractJSON returns the json document of the container output, an object or an array, leaving out the lines
+// logged around it
+func extractJSON(output string) (string, error) {
+	start := -1
+	offset := 0
+	for _, line := range strings.SplitAfter(output, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			start = offset + strings.IndexAny(line, "{[")
+			break
+		}
+		offset += len(line)
+	}
+
+	end := strings.LastIndexAny(output, "}]")
+	if start < 0 || end < start {
+		return "", errors.New("missing json document in flyway output")
+	}
+	return output[start : end+1], nil
+}
+
+func value[T any](v *T, fallback T) T {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
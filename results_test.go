@@ -0,0 +1,65 @@
+package flyway_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/CyberOwlTeam/flyway"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationInfo_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		json     string
+		expected flyway.MigrationInfo
+	}{
+		{
+			name: "success",
+			json: `{"category":"Versioned","version":"2.1","description":"create table stuff","type":"SQL","installedOnUTC":"2024-06-20T10:15:30.123","state":"Success","executionTime":42,"checksum":-1234567}`,
+			expected: flyway.MigrationInfo{
+				Category:      "Versioned",
+				Version:       "2.1",
+				Description:   "create table stuff",
+				Type:          "SQL",
+				State:         "Success",
+				InstalledOn:   time.Date(2024, 6, 20, 10, 15, 30, 123000000, time.UTC),
+				ExecutionTime: 42 * time.Millisecond,
+				Checksum:      -1234567,
+			},
+		},
+		{
+			name: "pending with nulls",
+			json: `{"version":"2.2","description":"alter table stuff","type":"SQL","installedOnUTC":null,"state":"Pending","executionTime":null,"checksum":null}`,
+			expected: flyway.MigrationInfo{
+				Version:     "2.2",
+				Description: "alter table stuff",
+				Type:        "SQL",
+				State:       "Pending",
+			},
+		},
+		{
+			name: "pending with empty values",
+			json: `{"version":"1","description":"create uuid extension","type":"SQL","installedOnUTC":"","state":"Pending","executionTime":0}`,
+			expected: flyway.MigrationInfo{
+				Version:     "1",
+				Description: "create uuid extension",
+				Type:        "SQL",
+				State:       "Pending",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			var info flyway.MigrationInfo
+			require.NoError(tt, json.Unmarshal([]byte(testCase.json), &info))
+			require.Equal(tt, testCase.expected, info)
+		})
+	}
+
+	var info flyway.MigrationInfo
+	require.Error(t, json.Unmarshal([]byte(`{"installedOnUTC":"yesterday"}`), &info), "expected an invalid time to be rejected")
+}
@@ -0,0 +1,99 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithRetry runs flyway again, in a new container, when a run fails for a transient reason: flyway could not
+// connect to the database, e.g. as it does not accept logins yet, or docker failed to create or start the
+// container, e.g. when pulling the image. SQL errors and timeouts are not retried. The run is attempted up to
+// attempts times, waiting backoff before the second attempt and twice as long before each following one.
+// Once the attempts are exhausted, the error carries the cause of each attempt.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) error {
+		if attempts <= 0 {
+			return fmt.Errorf("invalid retry attempts %d: expected a positive number", attempts)
+		}
+		if backoff < 0 {
+			return fmt.Errorf("invalid retry backoff %s: expected a non-negative duration", backoff)
+		}
+
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+		return nil
+	}
+}
+
+// RunContainerWithRetry is RunContainer with WithRetry(attempts, backoff), which retries the runs failing for a
+// transient reason, like a failed image pull or container start, rather than the deterministic migration errors
+func RunContainerWithRetry(ctx context.Context, attempts int, backoff time.Duration, opts ...testcontainers.ContainerCustomizer) (*FlywayContainer, error) {
+	return RunContainer(ctx, append(slices.Clone(opts), WithRetry(attempts, backoff))...)
+}
+
+// startContainerWithRetry is startContainer, retrying the transient failures as configured by WithRetry
+func startContainerWithRetry(ctx context.Context, req testcontainers.GenericContainerRequest, settings options) (*FlywayContainer, error) {
+	if settings.retryAttempts <= 1 {
+		return startContainer(ctx, req, settings)
+	}
+
+	var causes []error
+	backoff := settings.retryBackoff
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			// the file readers were consumed by the failed attempt
+			var err error
+			if attemptReq, err = cloneRequest(req); err != nil {
+				return nil, err
+			}
+			attemptReq.Reuse = req.Reuse
+		}
+
+		flywayContainer, err := startContainer(ctx, attemptReq, settings)
+		if err == nil {
+			return flywayContainer, nil
+		}
+		causes = append(causes, fmt.Errorf("attempt %d: %w", attempt, err))
+		if attempt == settings.retryAttempts || !retryable(ctx, err) {
+			if len(causes) == 1 {
+				return flywayContainer, err
+			}
+			return flywayContainer, fmt.Errorf("flyway failed after %d attempts: %w", attempt, errors.Join(causes...))
+		}
+
+		// the container of a failed flyway is returned to be inspected, which is left to the last attempt
+		if flywayContainer != nil {
+			if terminateErr := flywayContainer.Terminate(ctx); terminateErr != nil {
+				return nil, errors.Join(append(causes, terminateErr)...)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(append([]error{ctx.Err()}, causes...)...)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether the run failed for a transient reason, which WithRetry retries
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var migrationErr *MigrationError
+	if errors.As(err, &migrationErr) {
+		return errors.Is(migrationErr, ErrConnection)
+	}
+
+	var timeoutErr *TimeoutError
+	return !errors.As(err, &timeoutErr)
+}
@@ -0,0 +1,77 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// shared is the daemon container of Shared, started by the first caller and terminated once the last handle was
+// released. err is the error of the startup, returned to all the later callers, unless the context of its caller was
+// done: such a startup is retried by the next caller.
+var shared struct {
+	mu        sync.Mutex
+	container *FlywayContainer
+	err       error
+	refs      int
+}
+
+// Shared returns a handle to the daemon container shared by the tests of the test binary, so that they do not each
+// pay its startup. The first call starts it with the options, which the later calls ignore while it runs. Each
+// handle must be released with Terminate: the container is terminated once the last handle was released, or by
+// the testcontainers reaper once the test binary exited. If the startup failed, every call returns its error, but a
+// startup interrupted by the context of its caller, e.g. canceled or past its deadline, is retried by the next call.
+// Shared is safe for concurrent use, e.g. by parallel tests.
+func Shared(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (*FlywayContainer, error) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	if shared.err != nil {
+		return nil, fmt.Errorf("failed to start shared flyway container: %w", shared.err)
+	}
+
+	if shared.container == nil {
+		flywayContainer, err := RunContainer(ctx, append(slices.Clone(opts), WithDaemonMode())...)
+		if err != nil {
+			if flywayContainer != nil {
+				err = errors.Join(err, flywayContainer.Terminate(ctx))
+			}
+			if ctx.Err() == nil {
+				shared.err = err
+			}
+			return nil, fmt.Errorf("failed to start shared flyway container: %w", err)
+		}
+		shared.container = flywayContainer
+	}
+
+	shared.refs++
+	handle := *shared.container
+	handle.release = releaseShared(shared.container)
+	return &handle, nil
+}
+
+// releaseShared returns the release function of a handle to the shared container, which terminates the container
+// once the last handle was released. Releasing a handle again is a no-op.
+func releaseShared(flywayContainer *FlywayContainer) func(context.Context) error {
+	released := false
+	return func(ctx context.Context) error {
+		shared.mu.Lock()
+		defer shared.mu.Unlock()
+
+		if released {
+			return nil
+		}
+		released = true
+
+		shared.refs--
+		if shared.refs > 0 || shared.container != flywayContainer {
+			return nil
+		}
+		shared.container = nil
+		return flywayContainer.Terminate(ctx)
+	}
+}
@@ -0,0 +1,82 @@
+package flyway
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// DefaultSQLitePath is the container directory of the SQLite database of WithSQLite
+const DefaultSQLitePath = "/flyway/data"
+
+// WithSQLite migrates the SQLite database file name of the host directory, which is created when it is missing,
+// so that the test opens the migrated file directly, at (*FlywayContainer).SQLitePath. The directory is bind
+// mounted into the container, it must be local to the docker daemon. Flyway runs as the user of the test process,
+// so that it can write to the directory and the test owns the database file. The database url is set, and no user
+// nor password is needed.
+func WithSQLite(hostDir, name string) Option {
+	return func(o *options) error {
+		if strings.TrimSpace(hostDir) == "" {
+			return errors.New("invalid sqlite directory: path is empty")
+		}
+		if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+			return fmt.Errorf("invalid sqlite database %q: expected a file name", name)
+		}
+
+		absDir, err := filepath.Abs(hostDir)
+		if err != nil {
+			return fmt.Errorf("invalid sqlite directory: %w", err)
+		}
+		o.sqliteDir = absDir
+		o.sqliteName = name
+		return nil
+	}
+}
+
+// SQLitePath returns the host path of the SQLite database of WithSQLite, or an empty string without WithSQLite
+func (c *FlywayContainer) SQLitePath() string {
+	if c.settings.sqliteName == "" {
+		return ""
+	}
+	return filepath.Join(c.settings.sqliteDir, c.settings.sqliteName)
+}
+
+// withSQLiteDatabase mounts the directory of the SQLite database of WithSQLite and points flyway at the database
+func withSQLiteDatabase(req *testcontainers.GenericContainerRequest, settings options) error {
+	dbUrl := "jdbc:sqlite:" + path.Join(DefaultSQLitePath, settings.sqliteName)
+	if current := req.Env[flywayEnvUrlKey]; current != "" && current != dbUrl {
+		return fmt.Errorf("conflicting database url: WithSQLite sets %s, which environment variable %s overrides", dbUrl, flywayEnvUrlKey)
+	}
+
+	if err := os.MkdirAll(settings.sqliteDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create sqlite directory: %w", err)
+	}
+
+	// the files written by flyway belong to the test process, which can then open and remove them
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		req.User = fmt.Sprintf("%d:%d", uid, gid)
+	}
+
+	if err := withEnvSetting(flywayEnvUrlKey, dbUrl)(req); err != nil {
+		return err
+	}
+	return withHostConfigModifier(func(hostConfig *container.HostConfig) {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: settings.sqliteDir,
+			Target: DefaultSQLitePath,
+		})
+	})(req)
+}
+
+// isSQLite reports whether flyway migrates a SQLite database, which has no user nor password
+func isSQLite(req testcontainers.GenericContainerRequest) bool {
+	return strings.HasPrefix(req.Env[flywayEnvUrlKey], "jdbc:sqlite:")
+}
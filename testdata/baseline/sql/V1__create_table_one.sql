@@ -0,0 +1,4 @@
+CREATE TABLE one
+(
+    id INT NOT NULL PRIMARY KEY
+);
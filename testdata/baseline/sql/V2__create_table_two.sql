@@ -0,0 +1,4 @@
+CREATE TABLE two
+(
+    id INT NOT NULL PRIMARY KEY
+);
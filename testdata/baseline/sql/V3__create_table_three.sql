@@ -0,0 +1,4 @@
+CREATE TABLE three
+(
+    id INT NOT NULL PRIMARY KEY
+);
@@ -0,0 +1,4 @@
+CREATE TABLE four
+(
+    id INT NOT NULL PRIMARY KEY
+);
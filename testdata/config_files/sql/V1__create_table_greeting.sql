@@ -0,0 +1,6 @@
+CREATE TABLE greeting
+(
+    value TEXT NOT NULL
+);
+
+INSERT INTO greeting (value) VALUES ('${greeting}');
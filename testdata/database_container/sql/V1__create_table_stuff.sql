@@ -0,0 +1,8 @@
+CREATE TABLE stuff
+(
+    id   INT          NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+INSERT INTO stuff (id, name)
+VALUES (1, 'migrated');
@@ -0,0 +1,5 @@
+CREATE TABLE stuff
+(
+    id   SERIAL NOT NULL PRIMARY KEY,
+    name TEXT   NOT NULL
+);
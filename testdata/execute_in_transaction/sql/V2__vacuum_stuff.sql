@@ -0,0 +1 @@
+VACUUM stuff;
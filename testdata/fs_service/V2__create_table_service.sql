@@ -0,0 +1 @@
+CREATE TABLE service (id INT NOT NULL PRIMARY KEY, shared_id INT REFERENCES shared (id));
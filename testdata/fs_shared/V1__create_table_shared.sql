@@ -0,0 +1 @@
+CREATE TABLE shared (id INT NOT NULL PRIMARY KEY);
@@ -0,0 +1,5 @@
+CREATE TABLE app.stuff
+(
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
@@ -0,0 +1,5 @@
+CREATE TABLE reporting.stuff_summary
+(
+    name  VARCHAR(255) PRIMARY KEY,
+    total INTEGER NOT NULL
+);
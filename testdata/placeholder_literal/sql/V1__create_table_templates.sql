@@ -0,0 +1,9 @@
+CREATE TABLE templates
+(
+    id   SERIAL PRIMARY KEY,
+    body TEXT NOT NULL
+);
+
+-- the template syntax clashes with the flyway placeholders
+INSERT INTO templates (body)
+VALUES ('Hello ${notaplaceholder}');
@@ -0,0 +1 @@
+CREATE OR REPLACE VIEW greeting_messages AS SELECT message FROM greeting;
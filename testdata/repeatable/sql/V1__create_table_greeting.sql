@@ -0,0 +1 @@
+CREATE TABLE greeting (id SERIAL PRIMARY KEY, message TEXT NOT NULL);
@@ -0,0 +1 @@
+INSERT INTO greeting (message) VALUES ('hello');
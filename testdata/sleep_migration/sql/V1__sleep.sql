@@ -0,0 +1 @@
+SELECT pg_sleep(5);
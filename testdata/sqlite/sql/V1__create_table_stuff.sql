@@ -0,0 +1,5 @@
+CREATE TABLE stuff
+(
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL
+);
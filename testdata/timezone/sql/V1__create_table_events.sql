@@ -0,0 +1,6 @@
+CREATE TABLE events (
+    name       VARCHAR(255) NOT NULL,
+    utc_offset VARCHAR(6)   NOT NULL
+);
+
+INSERT INTO events (name, utc_offset) VALUES ('created', to_char(CURRENT_TIMESTAMP, 'TZH:TZM'));
@@ -0,0 +1 @@
+ALTER TABLE stuff ADD COLUMN created_timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();-- the checksum of this migration no longer matches the applied one
@@ -0,0 +1 @@
+ALTER TABLE stuff ADD COLUMN created_timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
\ No newline at end of file
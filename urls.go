@@ -0,0 +1,142 @@
+package flyway
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMySQLPort      = "3306"
+	defaultPostgresPort   = "5432"
+	defaultSQLServerPort  = "1433"
+	defaultOraclePort     = "1521"
+	defaultCockroachPort  = "26257"
+	defaultClickHousePort = "8123"
+)
+
+// MySQLNetworkURL builds the JDBC url of a MySQL database reachable as service on the container network.
+// An empty port falls back to the default MySQL port. It panics on an invalid service or port, or an empty db.
+func MySQLNetworkURL(service, port, db string) string {
+	return networkURL("mysql", service, port, defaultMySQLPort, db)
+}
+
+// MariaDBNetworkURL builds the JDBC url of a MariaDB database reachable as service on the container network,
+// using the MariaDB driver shipped with the flyway image. An empty port falls back to the default MariaDB port.
+// It panics on an invalid service or port, or an empty db.
+func MariaDBNetworkURL(service, port, db string) string {
+	return networkURL("mariadb", service, port, defaultMySQLPort, db)
+}
+
+// PostgresNetworkURL builds the JDBC url of a PostgreSQL database reachable as service on the container network.
+// An empty port falls back to the default PostgreSQL port. It panics on an invalid service or port, or an empty db.
+func PostgresNetworkURL(service, port, db string) string {
+	return networkURL("postgresql", service, port, defaultPostgresPort, db)
+}
+
+// PostgresURL builds the JDBC url of a PostgreSQL database reachable at host, with the params, e.g. sslmode, as
+// query parameters in name order. An empty port falls back to the default PostgreSQL port. It panics on an invalid
+// host or port, or an empty db.
+func PostgresURL(host, port, db string, params map[string]string) string {
+	return withQuery(networkURL("postgresql", host, port, defaultPostgresPort, db), params)
+}
+
+// CockroachURL builds the JDBC url of a CockroachDB database reachable at host, through the postgres driver which
+// flyway detects CockroachDB with, with the params as query parameters in name order. A node running in insecure
+// mode requires sslmode=disable. An empty port falls back to the default CockroachDB port. It panics on an invalid
+// host or port, or an empty db.
+func CockroachURL(host, port, db string, params map[string]string) string {
+	return withQuery(networkURL("postgresql", host, port, defaultCockroachPort, db), params)
+}
+
+// MariaDBURL builds the JDBC url of a MariaDB database reachable at host, using the MariaDB driver shipped with the
+// flyway image, with the params, e.g. useSsl, as query parameters in name order. An empty port falls back to the
+// default MariaDB port. It panics on an invalid host or port, or an empty db.
+func MariaDBURL(host, port, db string, params map[string]string) string {
+	return withQuery(networkURL("mariadb", host, port, defaultMySQLPort, db), params)
+}
+
+// withQuery appends the params to the url as query parameters in name order
+func withQuery(jdbcUrl string, params map[string]string) string {
+	if len(params) == 0 {
+		return jdbcUrl
+	}
+
+	query := url.Values{}
+	for name, value := range params {
+		query.Set(name, value)
+	}
+	return jdbcUrl + "?" + query.Encode()
+}
+
+// SQLServerNetworkURL builds the JDBC url of a SQL Server database reachable as service on the container network,
+// trusting the self-signed certificate of the server. An empty port falls back to the default SQL Server port.
+// It panics like SQLServerURL.
+func SQLServerNetworkURL(service, port, db string) string {
+	return SQLServerURL(service, port, db)
+}
+
+// SQLServerURL builds the JDBC url of a SQL Server database reachable at host. The connection is encrypted with
+// the self-signed certificate of the server trusted, without which the driver refuses to connect to the mssql
+// image. An empty port falls back to the default SQL Server port. It panics on an invalid host or port, or an empty
+// db or one which would add a connection property.
+func SQLServerURL(host, port, db string) string {
+	host, port = hostPort(host, port, defaultSQLServerPort)
+	db = strings.TrimSpace(db)
+	if db == "" || strings.ContainsAny(db, ";=") {
+		panic(fmt.Sprintf("invalid database url: invalid database %q", db))
+	}
+
+	return fmt.Sprintf("jdbc:sqlserver://%s:%s;databaseName=%s;encrypt=true;trustServerCertificate=true", host, port, db)
+}
+
+// networkURL builds the JDBC url, accepting ports in the docker "5432/tcp" notation too
+func networkURL(scheme, service, port, defaultPort, db string) string {
+	service, port = hostPort(service, port, defaultPort)
+	db = strings.TrimSpace(db)
+	if db == "" {
+		panic("invalid database url: database is empty")
+	}
+
+	return fmt.Sprintf("jdbc:%s://%s:%s/%s", scheme, service, port, url.PathEscape(db))
+}
+
+// hostPort validates the host and the port of a url, falling back to the default port. The builders panic on an
+// invalid one rather than returning an error, as they are called inline in the options of a test, where an invalid
+// host or port is a mistake of the test itself, like the invalid pattern of regexp.MustCompile.
+func hostPort(host, port, defaultPort string) (string, string) {
+	host = strings.TrimSpace(host)
+	if !databaseHostPattern.MatchString(host) {
+		panic(fmt.Sprintf("invalid database url: invalid host %q", host))
+	}
+
+	port = strings.TrimSuffix(strings.TrimSpace(port), "/tcp")
+	if port == "" {
+		port = defaultPort
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		panic(fmt.Sprintf("invalid database url: invalid port %q, expected a number or the docker notation, e.g. 5432/tcp", port))
+	}
+	return host, port
+}
+
+// OracleURL builds the JDBC url of an Oracle database service, e.g. FREEPDB1, reachable at host with the thin
+// driver shipped with the flyway image. An empty port falls back to the default Oracle listener port. It panics on
+// an invalid host or port, or an empty service.
+func OracleURL(host, port, service string) string {
+	host, port = hostPort(host, port, defaultOraclePort)
+	service = strings.TrimSpace(service)
+	if service == "" || strings.Contains(service, "/") {
+		panic(fmt.Sprintf("invalid database url: invalid service %q", service))
+	}
+
+	return fmt.Sprintf("jdbc:oracle:thin:@//%s:%s/%s", host, port, service)
+}
+
+// ClickHouseURL builds the JDBC url of a ClickHouse database reachable at host through the HTTP interface of the
+// server. An empty port falls back to the default ClickHouse HTTP port. It panics on an invalid host or port, or an
+// empty db.
+func ClickHouseURL(host, port, db string) string {
+	return networkURL("clickhouse", host, port, defaultClickHousePort, db)
+}
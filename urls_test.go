@@ -0,0 +1,95 @@
+package flyway_test
+
+import (
+	"testing"
+
+	"github.com/CyberOwlTeam/flyway"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      func(service, port, db string) string
+		service  string
+		port     string
+		db       string
+		expected string
+	}{
+		{name: "mysql", url: flyway.MySQLNetworkURL, service: "mysql", port: "3306", db: "test_db", expected: "jdbc:mysql://mysql:3306/test_db"},
+		{name: "mysql default port", url: flyway.MySQLNetworkURL, service: "mysql", db: "test_db", expected: "jdbc:mysql://mysql:3306/test_db"},
+		{name: "mysql custom port", url: flyway.MySQLNetworkURL, service: "db", port: "13306", db: "test_db", expected: "jdbc:mysql://db:13306/test_db"},
+		{name: "mariadb", url: flyway.MariaDBNetworkURL, service: "mariadb", db: "test_db", expected: "jdbc:mariadb://mariadb:3306/test_db"},
+		{name: "postgres", url: flyway.PostgresNetworkURL, service: "pgdb", port: "5432", db: "test_db", expected: "jdbc:postgresql://pgdb:5432/test_db"},
+		{name: "postgres default port", url: flyway.PostgresNetworkURL, service: "pgdb", db: "test_db", expected: "jdbc:postgresql://pgdb:5432/test_db"},
+		{name: "postgres docker port", url: flyway.PostgresNetworkURL, service: "pgdb", port: "15432/tcp", db: "test_db", expected: "jdbc:postgresql://pgdb:15432/test_db"},
+		{name: "surrounding spaces", url: flyway.PostgresNetworkURL, service: " pgdb ", port: " 5432 ", db: " test_db ", expected: "jdbc:postgresql://pgdb:5432/test_db"},
+		{name: "sqlserver", url: flyway.SQLServerNetworkURL, service: "mssql", db: "master", expected: "jdbc:sqlserver://mssql:1433;databaseName=master;encrypt=true;trustServerCertificate=true"},
+		{name: "escaped database", url: flyway.PostgresNetworkURL, service: "pgdb", db: "test db", expected: "jdbc:postgresql://pgdb:5432/test%20db"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			require.Equal(tt, testCase.expected, testCase.url(testCase.service, testCase.port, testCase.db))
+		})
+	}
+}
+
+func TestPostgresURL(t *testing.T) {
+	require.Equal(t, "jdbc:postgresql://pgdb:5432/test_db", flyway.PostgresURL("pgdb", "", "test_db", nil))
+	require.Equal(t, "jdbc:postgresql://localhost:15432/test_db?ApplicationName=flyway+tests&sslmode=disable",
+		flyway.PostgresURL("localhost", "15432/tcp", "test_db", map[string]string{"sslmode": "disable", "ApplicationName": "flyway tests"}))
+}
+
+func TestMariaDBURL(t *testing.T) {
+	require.Equal(t, "jdbc:mariadb://mariadb:3306/test_db", flyway.MariaDBURL("mariadb", "", "test_db", nil))
+	require.Equal(t, "jdbc:mariadb://localhost:13306/test_db?useSsl=false",
+		flyway.MariaDBURL("localhost", "13306", "test_db", map[string]string{"useSsl": "false"}))
+}
+
+func TestSQLServerURL(t *testing.T) {
+	require.Equal(t, "jdbc:sqlserver://localhost:11433;databaseName=test_db;encrypt=true;trustServerCertificate=true",
+		flyway.SQLServerURL("localhost", "11433/tcp", "test_db"))
+}
+
+func TestOracleURL(t *testing.T) {
+	require.Equal(t, "jdbc:oracle:thin:@//oracle:1521/FREEPDB1", flyway.OracleURL("oracle", "", "FREEPDB1"))
+	require.Equal(t, "jdbc:oracle:thin:@//localhost:11521/FREEPDB1", flyway.OracleURL("localhost", "11521/tcp", "FREEPDB1"))
+}
+
+func TestCockroachURL(t *testing.T) {
+	require.Equal(t, "jdbc:postgresql://cockroach:26257/defaultdb?sslmode=disable",
+		flyway.CockroachURL("cockroach", "", "defaultdb", map[string]string{"sslmode": "disable"}))
+}
+
+func TestClickHouseURL(t *testing.T) {
+	require.Equal(t, "jdbc:clickhouse://clickhouse:8123/test_db", flyway.ClickHouseURL("clickhouse", "", "test_db"))
+}
+
+func TestURL_invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  func() string
+	}{
+		{name: "empty service", url: func() string { return flyway.MySQLNetworkURL(" ", "", "test_db") }},
+		{name: "service with a path", url: func() string { return flyway.PostgresNetworkURL("pgdb/other", "", "test_db") }},
+		{name: "empty database", url: func() string { return flyway.PostgresNetworkURL("pgdb", "", "") }},
+		{name: "non-numeric port", url: func() string { return flyway.MariaDBNetworkURL("mariadb", "http", "test_db") }},
+		{name: "port with a path", url: func() string { return flyway.PostgresURL("pgdb", "5432/db", "test_db", nil) }},
+		{name: "udp port", url: func() string { return flyway.PostgresURL("pgdb", "5432/udp", "test_db", nil) }},
+		{name: "port out of range", url: func() string { return flyway.CockroachURL("cockroach", "65536", "defaultdb", nil) }},
+		{name: "zero port", url: func() string { return flyway.ClickHouseURL("clickhouse", "0", "test_db") }},
+		{name: "empty sqlserver host", url: func() string { return flyway.SQLServerURL("", "", "master") }},
+		{name: "sqlserver database with a property", url: func() string { return flyway.SQLServerNetworkURL("mssql", "", "master;encrypt=false") }},
+		{name: "empty oracle service", url: func() string { return flyway.OracleURL("oracle", "", "") }},
+		{name: "oracle service with a path", url: func() string { return flyway.OracleURL("oracle", "", "FREEPDB1/other") }},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			require.Panics(tt, func() { testCase.url() }, "expected the url to be rejected")
+		})
+	}
+}
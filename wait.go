@@ -0,0 +1,87 @@
+package flyway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// defaultPollInterval is the interval at which the completion strategy reads the container logs and state
+const defaultPollInterval = 100 * time.Millisecond
+
+var (
+	migratedPattern  = regexp.MustCompile(`Successfully applied \d+ migrations?|Schema .* is up to date`)
+	validatedPattern = regexp.MustCompile(`Successfully validated \d+ migrations?`)
+)
+
+// completionStrategy waits for the log line of flyway reporting the success of its command. It fails as soon as
+// flyway exits without reporting it, rather than waiting for the timeout.
+type completionStrategy struct {
+	pattern      *regexp.Regexp
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+var _ wait.StrategyTimeout = (*completionStrategy)(nil)
+
+func waitForCompletion(pattern *regexp.Regexp, timeout time.Duration) *completionStrategy {
+	return &completionStrategy{pattern: pattern, timeout: timeout, pollInterval: defaultPollInterval}
+}
+
+func (s *completionStrategy) Timeout() *time.Duration {
+	return &s.timeout
+}
+
+func (s *completionStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	for {
+		// the state is read before the logs, so that the logs of an exited container are complete
+		state, err := target.State(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get container state: %w", err)
+		}
+
+		matched, err := s.matchLogs(ctx, target)
+		if err != nil {
+			return err
+		}
+		if matched {
+			return nil
+		}
+
+		if !state.Running && state.Status == "exited" {
+			if state.ExitCode != 0 {
+				return fmt.Errorf("flyway exited with code %d before reporting success", state.ExitCode)
+			}
+			return errors.New("flyway exited without reporting success")
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("flyway did not report success within %s: %w", s.timeout, ctx.Err())
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// matchLogs reports whether the container logs contain the success log line
+func (s *completionStrategy) matchLogs(ctx context.Context, target wait.StrategyTarget) (bool, error) {
+	logs, err := target.Logs(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read container logs: %w", err)
+	}
+	defer logs.Close()
+
+	output, err := io.ReadAll(logs)
+	if err != nil {
+		return false, fmt.Errorf("failed to read container logs: %w", err)
+	}
+	return s.pattern.Match(output), nil
+}
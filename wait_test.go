@@ -0,0 +1,92 @@
+package flyway
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// logsTarget is a wait strategy target with fixed logs and state
+type logsTarget struct {
+	wait.StrategyTarget
+	logs  string
+	state types.ContainerState
+}
+
+func (t *logsTarget) Logs(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(t.logs)), nil
+}
+
+func (t *logsTarget) State(context.Context) (*types.ContainerState, error) {
+	return &t.state, nil
+}
+
+func TestCompletionStrategy(t *testing.T) {
+	running := types.ContainerState{Status: "running", Running: true}
+	succeeded := types.ContainerState{Status: "exited"}
+	failed := types.ContainerState{Status: "exited", ExitCode: 1}
+
+	testCases := []struct {
+		name     string
+		logs     string
+		state    types.ContainerState
+		timeout  time.Duration
+		expected string
+	}{
+		{
+			name:  "success",
+			logs:  `Successfully applied 3 migrations to schema "public", now at version v2.2 (execution time 00:00.021s)`,
+			state: running,
+		},
+		{
+			name:  "up to date",
+			logs:  `Schema "public" is up to date. No migration necessary.`,
+			state: succeeded,
+		},
+		{
+			name:     "failure before match",
+			logs:     `ERROR: Migration V2__create_table_stuff_again.sql failed`,
+			state:    failed,
+			expected: "flyway exited with code 1 before reporting success",
+		},
+		{
+			name:     "exit without match",
+			logs:     "Flyway Community Edition 10.15.0 by Redgate",
+			state:    succeeded,
+			expected: "flyway exited without reporting success",
+		},
+		{
+			name:     "timeout",
+			logs:     "Flyway Community Edition 10.15.0 by Redgate",
+			state:    running,
+			timeout:  200 * time.Millisecond,
+			expected: "flyway did not report success within 200ms",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(tt *testing.T) {
+			timeout := testCase.timeout
+			if timeout == 0 {
+				timeout = time.Minute
+			}
+
+			start := time.Now()
+			err := waitForCompletion(migratedPattern, timeout).WaitUntilReady(context.Background(), &logsTarget{logs: testCase.logs, state: testCase.state})
+			require.Less(tt, time.Since(start), 5*time.Second, "expected the wait not to hang")
+
+			if testCase.expected == "" {
+				require.NoError(tt, err)
+				return
+			}
+			require.ErrorContains(tt, err, testCase.expected)
+		})
+	}
+}